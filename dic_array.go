@@ -1,5 +1,7 @@
 package canopen
 
+import "context"
+
 type DicArray struct {
 	Description string
 	Index       uint16
@@ -75,6 +77,9 @@ func (array *DicArray) SetBoolVal(a bool)     {}
 func (array *DicArray) SetByteVal(a byte)     {}
 func (array *DicArray) IsDicVariable() bool   { return false }
 
+// ReadContext not applicable
+func (array *DicArray) ReadContext(ctx context.Context) error { return nil }
+
 func (array *DicArray) SetSDO(sdo *SDOClient) {
 	array.SDOClient = sdo
 }
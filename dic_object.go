@@ -1,5 +1,7 @@
 package canopen
 
+import "context"
+
 type DicObject interface {
 	// For DicRecord and DicArray
 
@@ -20,6 +22,7 @@ type DicObject interface {
 	GetOffset() int
 
 	Read() error
+	ReadContext(context.Context) error
 	Save() error
 
 	GetData() []byte
@@ -1,6 +1,11 @@
 package canopen
 
+import "sync"
+
 type DicObjectDic struct {
+	// Mutex guard the data of variables shared by the sdo servers and producers of a local node
+	sync.Mutex
+
 	Baudrate int
 	NodeID   int
 
@@ -1,5 +1,7 @@
 package canopen
 
+import "context"
+
 type DicRecord struct {
 	Description string
 	Index       uint16
@@ -80,6 +82,9 @@ func (record *DicRecord) SetBoolVal(a bool)     {}
 func (record *DicRecord) SetByteVal(a byte)     {}
 func (record *DicRecord) IsDicVariable() bool   { return false }
 
+// ReadContext not applicable
+func (record *DicRecord) ReadContext(ctx context.Context) error { return nil }
+
 // SetSDO to DicRecord
 func (record *DicRecord) SetSDO(sdo *SDOClient) {
 	record.SDOClient = sdo
@@ -28,6 +28,9 @@ const (
 	OctetString   byte = 0xa
 	UnicodeString byte = 0xb
 	Domain        byte = 0xf
+
+	TimeOfDay      byte = 0xc
+	TimeDifference byte = 0xd
 )
 
 func IsSignedType(t byte) bool {
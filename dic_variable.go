@@ -1,9 +1,13 @@
 package canopen
 
 import (
+	"context"
 	"encoding/ascii85"
 	"encoding/binary"
 	"errors"
+	"math"
+	"strconv"
+	"strings"
 	"unicode/utf16"
 )
 
@@ -57,8 +61,12 @@ func (variable *DicVariable) FindName(name string) DicObject {
 	return nil
 }
 
+// SetSDO set the sdo client of variable, only written on change as members
+// get the client of their parent on each lookup
 func (variable *DicVariable) SetSDO(sdo *SDOClient) {
-	variable.SDOClient = sdo
+	if variable.SDOClient != sdo {
+		variable.SDOClient = sdo
+	}
 }
 
 func (variable *DicVariable) IsDicVariable() bool {
@@ -116,6 +124,10 @@ func (variable *DicVariable) GetDataLen() int {
 		l = 8
 	}
 
+	if variable.DataType == TimeOfDay || variable.DataType == TimeDifference {
+		l = 6
+	}
+
 	return l * 8
 }
 
@@ -141,11 +153,16 @@ func (variable *DicVariable) AddBitDefinition(name string, bits []byte) {
 
 // Read variable value using SDO
 func (variable *DicVariable) Read() error {
+	return variable.ReadContext(context.Background())
+}
+
+// ReadContext read variable value using SDO, until ctx is done
+func (variable *DicVariable) ReadContext(ctx context.Context) error {
 	if variable.SDOClient == nil {
 		return errors.New("SDOClient required")
 	}
 
-	data, err := variable.SDOClient.Read(variable.Index, variable.SubIndex)
+	data, err := variable.SDOClient.ReadContext(ctx, variable.Index, variable.SubIndex)
 	if err != nil {
 		return err
 	}
@@ -174,6 +191,57 @@ func (variable *DicVariable) Save() error {
 	return variable.Write(variable.Data)
 }
 
+// DefaultData returns the Default value encoded as variable DataType.
+// A $NODEID in the default value is replaced by nodeID.
+func (variable *DicVariable) DefaultData(nodeID int) []byte {
+	def := string(variable.Default)
+
+	if IsDataType(variable.DataType) {
+		if variable.DataType == Domain {
+			return []byte{}
+		}
+
+		return []byte(def)
+	}
+
+	def = strings.ReplaceAll(strings.ToLower(def), " ", "")
+	offset := int64(0)
+
+	if strings.Contains(def, "$nodeid") {
+		offset = int64(nodeID)
+		def = strings.Trim(strings.ReplaceAll(def, "$nodeid", ""), "+")
+	}
+
+	data := make([]byte, variable.GetDataLen()/8)
+
+	if IsFloatType(variable.DataType) {
+		v, _ := strconv.ParseFloat(def, 64)
+
+		if variable.DataType == Real32 {
+			binary.LittleEndian.PutUint32(data, math.Float32bits(float32(v)))
+		} else {
+			binary.LittleEndian.PutUint64(data, math.Float64bits(v))
+		}
+
+		return data
+	}
+
+	v := offset
+	if len(def) > 0 {
+		if i, err := strconv.ParseInt(def, 0, 64); err == nil {
+			v += i
+		} else if u, err := strconv.ParseUint(def, 0, 64); err == nil {
+			v += int64(u)
+		}
+	}
+
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, uint64(v))
+	copy(data, buf)
+
+	return data
+}
+
 func (variable *DicVariable) IsDomainDataType() bool {
 	return variable.DataType == Domain
 }
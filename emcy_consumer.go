@@ -0,0 +1,376 @@
+package canopen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaster-prj/go-can"
+)
+
+// EmcyCobIDInvalid is the bit 31 of the emcy COB-ID 0x1014, set when emcy is not valid
+const EmcyCobIDInvalid uint32 = 0x80000000
+
+// emcyEventsChanSize is the buffer size of emcy events chans
+const emcyEventsChanSize = 16
+
+// emcyHistorySize is the maximum number of errors kept in history
+const emcyHistorySize = 254
+
+// emcyErrorClasses describe CiA 301 error codes, most specific masks first
+var emcyErrorClasses = []struct {
+	code        uint16
+	mask        uint16
+	description string
+}{
+	{0x0000, 0xFF00, "Error reset or no error"},
+	{0x8110, 0xFFFF, "CAN overrun (objects lost)"},
+	{0x8120, 0xFFFF, "CAN in error passive mode"},
+	{0x8130, 0xFFFF, "Life guard error or heartbeat error"},
+	{0x8140, 0xFFFF, "Recovered from bus off"},
+	{0x8150, 0xFFFF, "CAN-ID collision"},
+	{0x8210, 0xFFFF, "PDO not processed due to length error"},
+	{0x8220, 0xFFFF, "PDO length exceeded"},
+	{0x8230, 0xFFFF, "DAM MPDO not processed, destination object not available"},
+	{0x8240, 0xFFFF, "Unexpected SYNC data length"},
+	{0x8250, 0xFFFF, "RPDO timeout"},
+	{0x1000, 0xFF00, "Generic error"},
+	{0x2100, 0xFF00, "Current, device input side"},
+	{0x2200, 0xFF00, "Current inside the device"},
+	{0x2300, 0xFF00, "Current, device output side"},
+	{0x3100, 0xFF00, "Mains voltage"},
+	{0x3200, 0xFF00, "Voltage inside the device"},
+	{0x3300, 0xFF00, "Output voltage"},
+	{0x4100, 0xFF00, "Ambient temperature"},
+	{0x4200, 0xFF00, "Device temperature"},
+	{0x6100, 0xFF00, "Internal software"},
+	{0x6200, 0xFF00, "User software"},
+	{0x6300, 0xFF00, "Data set"},
+	{0x8100, 0xFF00, "Communication"},
+	{0x8200, 0xFF00, "Protocol error"},
+	{0xFF00, 0xFF00, "Device specific"},
+	{0x2000, 0xF000, "Current"},
+	{0x3000, 0xF000, "Voltage"},
+	{0x4000, 0xF000, "Temperature"},
+	{0x5000, 0xF000, "Device hardware"},
+	{0x6000, 0xF000, "Device software"},
+	{0x7000, 0xF000, "Additional modules"},
+	{0x8000, 0xF000, "Monitoring"},
+	{0x9000, 0xF000, "External error"},
+	{0xF000, 0xF000, "Additional functions"},
+}
+
+// EmcyErrorRegisterBits describe the bits of the error register 0x1001
+var EmcyErrorRegisterBits = map[uint8]string{
+	0x01: "Generic error",
+	0x02: "Current",
+	0x04: "Voltage",
+	0x08: "Temperature",
+	0x10: "Communication error",
+	0x20: "Device profile specific",
+	0x80: "Manufacturer specific",
+}
+
+// EmcyError is an emergency message sent by a node
+type EmcyError struct {
+	NodeID int
+	// Code is the CiA 301 error code, 0x0000 for an error reset
+	Code uint16
+	// Register is the error register 0x1001 of the node
+	Register uint8
+	// Data is manufacturer specific
+	Data      [5]byte
+	Timestamp time.Time
+}
+
+// IsReset returns true for an error reset, or no error, message
+func (e EmcyError) IsReset() bool {
+	return e.Code&0xFF00 == 0x0000
+}
+
+// Description returns the description of the error code class
+func (e EmcyError) Description() string {
+	for _, class := range emcyErrorClasses {
+		if e.Code&class.mask == class.code {
+			return class.description
+		}
+	}
+
+	return ""
+}
+
+// RegisterDescriptions returns the description of each bit set in the error register
+func (e EmcyError) RegisterDescriptions() []string {
+	descriptions := []string{}
+
+	for bit := 0; bit < 8; bit++ {
+		if description, ok := EmcyErrorRegisterBits[1<<bit]; ok && e.Register&(1<<bit) != 0 {
+			descriptions = append(descriptions, description)
+		}
+	}
+
+	return descriptions
+}
+
+func (e EmcyError) Error() string {
+	description := e.Description()
+	if registers := e.RegisterDescriptions(); len(registers) > 0 {
+		description += " (" + strings.Join(registers, ", ") + ")"
+	}
+
+	return fmt.Sprintf("emcy of node %d: 0x%04X %s", e.NodeID, e.Code, description)
+}
+
+// decodeEmcyError decode an emcy frame, nil if data is not 8 bytes
+func decodeEmcyError(nodeID int, data []byte, timestamp time.Time) *EmcyError {
+	if len(data) != 8 {
+		return nil
+	}
+
+	emcy := &EmcyError{
+		NodeID:    nodeID,
+		Code:      binary.LittleEndian.Uint16(data[0:2]),
+		Register:  data[2],
+		Timestamp: timestamp,
+	}
+	copy(emcy.Data[:], data[3:8])
+
+	return emcy
+}
+
+type EmcyEventsChan struct {
+	chanID string
+	C      chan EmcyError
+}
+
+// EmcyConsumer receive emergency messages of a node
+type EmcyConsumer struct {
+	sync.Mutex
+
+	NodeID  int
+	Network *Network
+	// CobID of emcy messages, 0x80+NodeID by default
+	CobID uint32
+
+	// Active errors since the last error reset
+	Active []EmcyError
+	// History of errors, newest last, error resets excluded
+	History []EmcyError
+
+	eventsChans []*EmcyEventsChan
+
+	listening    bool
+	stopChan     chan bool
+	framesChanID string
+}
+
+// NewEmcyConsumer return a new EmcyConsumer using the default COB-ID
+func NewEmcyConsumer(nodeID int, network *Network) *EmcyConsumer {
+	return &EmcyConsumer{
+		NodeID:  nodeID,
+		Network: network,
+		CobID:   uint32(0x80 + nodeID),
+	}
+}
+
+// Listen for emcy messages on network
+func (consumer *EmcyConsumer) Listen() error {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	if consumer.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	if consumer.listening {
+		return nil
+	}
+
+	if consumer.CobID&EmcyCobIDInvalid != 0 {
+		return nil
+	}
+
+	cobID := consumer.CobID & 0x7FF
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == cobID
+	}
+
+	framesChan := consumer.Network.AcquireFramesChan(&filterFunc)
+	consumer.framesChanID = framesChan.ID
+	consumer.stopChan = make(chan bool)
+	consumer.listening = true
+
+	go func(stopChan chan bool) {
+		for {
+			select {
+			case <-stopChan:
+				return
+			case frm, ok := <-framesChan.C:
+				if !ok {
+					return
+				}
+				if emcy := decodeEmcyError(consumer.NodeID, frm.GetData(), time.Now()); emcy != nil {
+					consumer.handleEmcy(*emcy)
+				}
+			}
+		}
+	}(consumer.stopChan)
+
+	return nil
+}
+
+// Unlisten stop listening emcy messages
+func (consumer *EmcyConsumer) Unlisten() {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	if !consumer.listening {
+		return
+	}
+
+	close(consumer.stopChan)
+	consumer.Network.ReleaseFramesChan(consumer.framesChanID)
+	consumer.listening = false
+}
+
+// SetCobID change the COB-ID of emcy messages, listening is restarted if needed
+func (consumer *EmcyConsumer) SetCobID(cobID uint32) error {
+	consumer.Lock()
+	listening := consumer.listening
+	consumer.Unlock()
+
+	consumer.Unlisten()
+
+	consumer.Lock()
+	consumer.CobID = cobID
+	consumer.Unlock()
+
+	if listening {
+		return consumer.Listen()
+	}
+
+	return nil
+}
+
+func (consumer *EmcyConsumer) handleEmcy(emcy EmcyError) {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	if emcy.IsReset() {
+		consumer.Active = nil
+	} else {
+		active := []EmcyError{}
+		for _, activeEmcy := range consumer.Active {
+			if activeEmcy.Code != emcy.Code {
+				active = append(active, activeEmcy)
+			}
+		}
+		consumer.Active = append(active, emcy)
+
+		consumer.History = append(consumer.History, emcy)
+		if len(consumer.History) > emcyHistorySize {
+			consumer.History = consumer.History[len(consumer.History)-emcyHistorySize:]
+		}
+	}
+
+	for _, eventsChan := range consumer.eventsChans {
+		select {
+		case eventsChan.C <- emcy:
+		default:
+		}
+	}
+}
+
+// GetActive returns a copy of the active errors
+func (consumer *EmcyConsumer) GetActive() []EmcyError {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	return append([]EmcyError{}, consumer.Active...)
+}
+
+// GetHistory returns a copy of the errors history, newest last
+func (consumer *EmcyConsumer) GetHistory() []EmcyError {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	return append([]EmcyError{}, consumer.History...)
+}
+
+// ClearHistory forget errors received
+func (consumer *EmcyConsumer) ClearHistory() {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	consumer.History = nil
+}
+
+// Wait return the next emcy received, or ctx error when ctx is done
+func (consumer *EmcyConsumer) Wait(ctx context.Context) (*EmcyError, error) {
+	eventsChan := consumer.AcquireEventsChan()
+	defer consumer.ReleaseEventsChan(eventsChan.chanID)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case emcy := <-eventsChan.C:
+		return &emcy, nil
+	}
+}
+
+// AcquireEventsChan create a new EmcyEventsChan
+func (consumer *EmcyConsumer) AcquireEventsChan() *EmcyEventsChan {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	eventsChan := &EmcyEventsChan{
+		chanID: uuid.Must(uuid.NewRandom()).String(),
+		C:      make(chan EmcyError, emcyEventsChanSize),
+	}
+
+	consumer.eventsChans = append(consumer.eventsChans, eventsChan)
+
+	return eventsChan
+}
+
+// ReleaseEventsChan release (close) a EmcyEventsChan
+func (consumer *EmcyConsumer) ReleaseEventsChan(id string) error {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	for idx, eventsChan := range consumer.eventsChans {
+		if eventsChan.chanID == id {
+			close(eventsChan.C)
+			consumer.eventsChans = append(
+				consumer.eventsChans[:idx],
+				consumer.eventsChans[idx+1:]...,
+			)
+			return nil
+		}
+	}
+
+	return errors.New("no EmcyEventsChan found with specified ID")
+}
+
+// ConfigureEmcyConsumer read the emcy COB-ID 0x1014 of the node, and listen on it
+func (node *Node) ConfigureEmcyConsumer(ctx context.Context) error {
+	if node.SDOClient == nil || node.EmcyConsumer == nil {
+		return errors.New("node not initialized")
+	}
+
+	data, err := node.SDOClient.ReadContext(ctx, 0x1014, 0)
+	if err != nil {
+		return err
+	}
+
+	if len(data) != 4 {
+		return fmt.Errorf("%w: emcy COB-ID has %d bytes", ErrSDODataLength, len(data))
+	}
+
+	return node.EmcyConsumer.SetCobID(binary.LittleEndian.Uint32(data))
+}
@@ -0,0 +1,112 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmcyError_Decode(t *testing.T) {
+	emcy := decodeEmcyError(5, []byte{0x30, 0x81, 0x11, 0x01, 0x02, 0x03, 0x04, 0x05}, time.Now())
+	if assert.NotNil(t, emcy) {
+		assert.Equal(t, uint16(0x8130), emcy.Code)
+		assert.Equal(t, uint8(0x11), emcy.Register)
+		assert.Equal(t, [5]byte{1, 2, 3, 4, 5}, emcy.Data)
+		assert.False(t, emcy.IsReset())
+		assert.Equal(t, "Life guard error or heartbeat error", emcy.Description())
+		assert.Equal(t, []string{"Generic error", "Communication error"}, emcy.RegisterDescriptions())
+		assert.Equal(t, "emcy of node 5: 0x8130 Life guard error or heartbeat error (Generic error, Communication error)", emcy.Error())
+	}
+
+	assert.Equal(t, "Communication", EmcyError{Code: 0x8101}.Description())
+	assert.Equal(t, "Monitoring", EmcyError{Code: 0x8301}.Description())
+	assert.Equal(t, "Device specific", EmcyError{Code: 0xFF42}.Description())
+	assert.True(t, EmcyError{Code: 0x0000}.IsReset())
+
+	assert.Nil(t, decodeEmcyError(5, []byte{0x00, 0x00}, time.Now()))
+}
+
+func TestEmcyConsumer(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	eventsChan := node.EmcyConsumer.AcquireEventsChan()
+	defer node.EmcyConsumer.ReleaseEventsChan(eventsChan.chanID)
+
+	waitEmcy := func() EmcyError {
+		t.Helper()
+
+		select {
+		case emcy := <-eventsChan.C:
+			return emcy
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("no emcy received")
+		}
+
+		return EmcyError{}
+	}
+
+	network.Send(0x85, []byte{0x10, 0x42, 0x09, 0, 0, 0, 0, 0})
+	network.Send(0x85, []byte{0x30, 0x81, 0x11, 0, 0, 0, 0, 0})
+	network.Send(0x85, []byte{0x10, 0x42, 0x09, 0, 0, 0, 0, 1})
+	// Other node
+	network.Send(0x86, []byte{0x00, 0x10, 0x01, 0, 0, 0, 0, 0})
+
+	assert.Equal(t, uint16(0x4210), waitEmcy().Code)
+	assert.Equal(t, uint16(0x8130), waitEmcy().Code)
+	assert.Equal(t, uint16(0x4210), waitEmcy().Code)
+
+	active := node.EmcyConsumer.GetActive()
+	if assert.Len(t, active, 2) {
+		assert.Equal(t, uint16(0x8130), active[0].Code)
+		assert.Equal(t, [5]byte{0, 0, 0, 0, 1}, active[1].Data)
+	}
+	assert.Len(t, node.EmcyConsumer.GetHistory(), 3)
+
+	// Error reset
+	network.Send(0x85, []byte{0x00, 0x00, 0x00, 0, 0, 0, 0, 0})
+	assert.True(t, waitEmcy().IsReset())
+	assert.Empty(t, node.EmcyConsumer.GetActive())
+	assert.Len(t, node.EmcyConsumer.GetHistory(), 3)
+
+	node.EmcyConsumer.ClearHistory()
+	assert.Empty(t, node.EmcyConsumer.GetHistory())
+
+	// New COB-ID
+	assert.NoError(t, node.EmcyConsumer.SetCobID(0x185))
+	network.Send(0x85, []byte{0x00, 0x10, 0x01, 0, 0, 0, 0, 0})
+	network.Send(0x185, []byte{0x00, 0x50, 0x01, 0, 0, 0, 0, 0})
+	assert.Equal(t, uint16(0x5000), waitEmcy().Code)
+
+	// Invalid COB-ID
+	assert.NoError(t, node.EmcyConsumer.SetCobID(EmcyCobIDInvalid|0x185))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	go network.Send(0x185, []byte{0x00, 0x50, 0x01, 0, 0, 0, 0, 0})
+	_, err := node.EmcyConsumer.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNode_ConfigureEmcyConsumer(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+	localNode.ObjectDic.AddObject(&DicVariable{Index: 0x1014, Name: "COB-ID EMCY", DataType: Unsigned32, AccessType: "rw", Data: []byte{0x85, 0x02, 0x00, 0x00}})
+
+	assert.NoError(t, node.ConfigureEmcyConsumer(context.Background()))
+	assert.Equal(t, uint32(0x285), node.EmcyConsumer.CobID)
+
+	eventsChan := node.EmcyConsumer.AcquireEventsChan()
+	defer node.EmcyConsumer.ReleaseEventsChan(eventsChan.chanID)
+
+	localNode.Network.Send(0x285, []byte{0x00, 0x10, 0x01, 0, 0, 0, 0, 0})
+
+	select {
+	case emcy := <-eventsChan.C:
+		assert.Equal(t, uint16(0x1000), emcy.Code)
+		assert.Equal(t, 5, emcy.NodeID)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("no emcy received")
+	}
+}
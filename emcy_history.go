@@ -0,0 +1,81 @@
+package canopen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// EmcyHistoryEntry is an entry of the pre-defined error field 0x1003
+type EmcyHistoryEntry struct {
+	// Code is the CiA 301 error code
+	Code uint16
+	// ManufacturerInfo is the manufacturer specific additional information
+	ManufacturerInfo uint16
+}
+
+// Description returns the description of the error code class
+func (entry EmcyHistoryEntry) Description() string {
+	return EmcyError{Code: entry.Code}.Description()
+}
+
+func (entry EmcyHistoryEntry) String() string {
+	return fmt.Sprintf("0x%04X %s (0x%04X)", entry.Code, entry.Description(), entry.ManufacturerInfo)
+}
+
+// ErrorHistory read the pre-defined error field 0x1003 of the node, newest error first
+func (node *Node) ErrorHistory() ([]EmcyHistoryEntry, error) {
+	return node.ErrorHistoryContext(context.Background())
+}
+
+// ErrorHistoryContext read the pre-defined error field 0x1003 of the node, newest error first,
+// until ctx is done
+func (node *Node) ErrorHistoryContext(ctx context.Context) ([]EmcyHistoryEntry, error) {
+	if node.SDOClient == nil {
+		return nil, errors.New("node not initialized")
+	}
+
+	count, err := node.SDOClient.ReadContext(ctx, 0x1003, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(count) != 1 {
+		return nil, fmt.Errorf("%w: number of errors has %d bytes", ErrSDODataLength, len(count))
+	}
+
+	entries := make([]EmcyHistoryEntry, 0, count[0])
+	for subIndex := uint8(1); subIndex <= count[0]; subIndex++ {
+		data, err := node.SDOClient.ReadContext(ctx, 0x1003, subIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) != 4 {
+			return nil, fmt.Errorf("%w: error field %d has %d bytes", ErrSDODataLength, subIndex, len(data))
+		}
+
+		value := binary.LittleEndian.Uint32(data)
+		entries = append(entries, EmcyHistoryEntry{
+			Code:             uint16(value),
+			ManufacturerInfo: uint16(value >> 16),
+		})
+	}
+
+	return entries, nil
+}
+
+// ClearErrorHistory clear the pre-defined error field 0x1003 of the node
+func (node *Node) ClearErrorHistory() error {
+	return node.ClearErrorHistoryContext(context.Background())
+}
+
+// ClearErrorHistoryContext clear the pre-defined error field 0x1003 of the node, until ctx is done
+func (node *Node) ClearErrorHistoryContext(ctx context.Context) error {
+	if node.SDOClient == nil {
+		return errors.New("node not initialized")
+	}
+
+	return node.SDOClient.WriteContext(ctx, 0x1003, 0, false, []byte{0x00})
+}
@@ -0,0 +1,39 @@
+package canopen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNode_ErrorHistory(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+
+	entries, err := node.ErrorHistory()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	assert.NoError(t, localNode.EmcyProducer.Raise(0x3210, 0x04, nil))
+	assert.NoError(t, localNode.EmcyProducer.Raise(0x8250, 0x10, nil))
+	localNode.ObjectDic.FindIndex(0x1003).FindIndex(2).SetData([]byte{0x10, 0x32, 0x2A, 0x00})
+
+	entries, err = node.ErrorHistory()
+	assert.NoError(t, err)
+	assert.Equal(t, []EmcyHistoryEntry{
+		{Code: 0x8250},
+		{Code: 0x3210, ManufacturerInfo: 0x2A},
+	}, entries)
+	assert.Equal(t, "RPDO timeout", entries[0].Description())
+	assert.Equal(t, "0x3210 Voltage inside the device (0x002A)", entries[1].String())
+
+	assert.NoError(t, node.ClearErrorHistory())
+
+	entries, err = node.ErrorHistory()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// Missing object
+	delete(localNode.ObjectDic.Indexes, 0x1003)
+	_, err = node.ErrorHistory()
+	assertSDOAbortCode(t, err, SDOAbortObjectDoesNotExist)
+}
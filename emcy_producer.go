@@ -0,0 +1,349 @@
+package canopen
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+// emcyGenericErrorBit is the generic error bit of the error register 0x1001,
+// set as long as an error is active
+const emcyGenericErrorBit uint8 = 0x01
+
+// emcyActiveError is an error raised and not yet cleared
+type emcyActiveError struct {
+	code     uint16
+	register uint8
+}
+
+// EmcyProducer send emergency messages of a local node, and maintain the error register 0x1001
+// and the pre-defined error field 0x1003 of its object dictionary
+type EmcyProducer struct {
+	sync.Mutex
+
+	NodeID    int
+	Network   *Network
+	ObjectDic *DicObjectDic
+
+	// CobID of emcy messages, from 0x1014 or 0x80+NodeID
+	CobID uint32
+	// InhibitTime is the minimum time between two emcy messages, from 0x1015
+	InhibitTime time.Duration
+
+	active   []emcyActiveError
+	queue    [][]byte
+	lastSent time.Time
+	timer    *time.Timer
+}
+
+// NewEmcyProducer return a new EmcyProducer configured by 0x1014 and 0x1015 of objectDic
+func NewEmcyProducer(nodeID int, network *Network, objectDic *DicObjectDic) *EmcyProducer {
+	producer := &EmcyProducer{
+		NodeID:    nodeID,
+		Network:   network,
+		ObjectDic: objectDic,
+		CobID:     uint32(0x80 + nodeID),
+	}
+
+	if objectDic != nil {
+		if object := objectDic.FindIndex(0x1014); object != nil && len(object.GetData()) == 4 {
+			producer.CobID = binary.LittleEndian.Uint32(object.GetData())
+		}
+
+		if object := objectDic.FindIndex(0x1015); object != nil && len(object.GetData()) == 2 {
+			producer.InhibitTime = time.Duration(binary.LittleEndian.Uint16(object.GetData())) * 100 * time.Microsecond
+		}
+	}
+
+	return producer
+}
+
+// Raise send an emcy with code, register bits and up to 5 bytes of manufacturer data,
+// and add the error to the active errors and to the pre-defined error field
+func (producer *EmcyProducer) Raise(code uint16, register uint8, data []byte) error {
+	if code&0xFF00 == 0 {
+		return errors.New("error code 0x00XX is reserved for error reset")
+	}
+
+	if len(data) > 5 {
+		return errors.New("emcy manufacturer data is limited to 5 bytes")
+	}
+
+	producer.Lock()
+	defer producer.Unlock()
+
+	active := []emcyActiveError{}
+	for _, activeError := range producer.active {
+		if activeError.code != code {
+			active = append(active, activeError)
+		}
+	}
+	producer.active = append(active, emcyActiveError{code: code, register: register})
+
+	producer.addErrorField(code)
+
+	return producer.send(code, producer.updateErrorRegister(), data)
+}
+
+// Clear remove code from the active errors, the error reset message is sent
+// when the last active error is cleared
+func (producer *EmcyProducer) Clear(code uint16) error {
+	producer.Lock()
+	defer producer.Unlock()
+
+	active := []emcyActiveError{}
+	for _, activeError := range producer.active {
+		if activeError.code != code {
+			active = append(active, activeError)
+		}
+	}
+
+	if len(active) == len(producer.active) {
+		return nil
+	}
+	producer.active = active
+
+	register := producer.updateErrorRegister()
+	if len(active) > 0 {
+		return nil
+	}
+
+	return producer.send(0x0000, register, nil)
+}
+
+// ClearAll remove all active errors, and send the error reset message
+func (producer *EmcyProducer) ClearAll() error {
+	producer.Lock()
+	defer producer.Unlock()
+
+	if len(producer.active) == 0 {
+		return nil
+	}
+	producer.active = nil
+
+	return producer.send(0x0000, producer.updateErrorRegister(), nil)
+}
+
+// ActiveErrors returns the codes of active errors, oldest first
+func (producer *EmcyProducer) ActiveErrors() []uint16 {
+	producer.Lock()
+	defer producer.Unlock()
+
+	codes := []uint16{}
+	for _, activeError := range producer.active {
+		codes = append(codes, activeError.code)
+	}
+
+	return codes
+}
+
+// ErrorRegister returns the error register of active errors
+func (producer *EmcyProducer) ErrorRegister() uint8 {
+	producer.Lock()
+	defer producer.Unlock()
+
+	return producer.errorRegister()
+}
+
+func (producer *EmcyProducer) errorRegister() uint8 {
+	if len(producer.active) == 0 {
+		return 0
+	}
+
+	register := emcyGenericErrorBit
+	for _, activeError := range producer.active {
+		register |= activeError.register
+	}
+
+	return register
+}
+
+// updateErrorRegister store the error register in 0x1001, and returns it
+func (producer *EmcyProducer) updateErrorRegister() uint8 {
+	register := producer.errorRegister()
+
+	if producer.ObjectDic != nil {
+		if object := producer.ObjectDic.FindIndex(0x1001); object != nil {
+			producer.ObjectDic.Lock()
+			object.SetData([]byte{register})
+			producer.ObjectDic.Unlock()
+		}
+	}
+
+	return register
+}
+
+// addErrorField insert code as newest error of the pre-defined error field 0x1003
+func (producer *EmcyProducer) addErrorField(code uint16) {
+	if producer.ObjectDic == nil {
+		return
+	}
+
+	object := producer.ObjectDic.FindIndex(0x1003)
+	if object == nil || object.FindIndex(0) == nil {
+		return
+	}
+
+	// Number of errors the field can hold
+	size := 0
+	for object.FindIndex(uint16(size+1)) != nil {
+		size++
+	}
+	if size == 0 {
+		return
+	}
+
+	producer.ObjectDic.Lock()
+	defer producer.ObjectDic.Unlock()
+
+	count := 0
+	if data := object.FindIndex(0).GetData(); len(data) == 1 {
+		count = int(data[0])
+	}
+	if count >= size {
+		count = size - 1
+	}
+
+	// Shift older errors
+	for subIndex := count; subIndex >= 1; subIndex-- {
+		data := object.FindIndex(uint16(subIndex)).GetData()
+		object.FindIndex(uint16(subIndex + 1)).SetData(append([]byte{}, data...))
+	}
+
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, uint32(code))
+	object.FindIndex(1).SetData(data)
+	object.FindIndex(0).SetData([]byte{uint8(count + 1)})
+}
+
+// send the emcy frame, now or after the inhibit time
+func (producer *EmcyProducer) send(code uint16, register uint8, data []byte) error {
+	if producer.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	if producer.CobID&EmcyCobIDInvalid != 0 {
+		return nil
+	}
+
+	frame := make([]byte, 8)
+	binary.LittleEndian.PutUint16(frame, code)
+	frame[2] = register
+	copy(frame[3:], data)
+
+	since := time.Since(producer.lastSent)
+	if producer.timer == nil && since >= producer.InhibitTime {
+		producer.lastSent = time.Now()
+		return producer.Network.Send(producer.CobID&0x7FF, frame)
+	}
+
+	producer.queue = append(producer.queue, frame)
+	if producer.timer == nil {
+		producer.timer = time.AfterFunc(producer.InhibitTime-since, producer.flush)
+	}
+
+	return nil
+}
+
+// flush send the next queued frame, at most one per inhibit time
+func (producer *EmcyProducer) flush() {
+	producer.Lock()
+	defer producer.Unlock()
+
+	if len(producer.queue) == 0 {
+		producer.timer = nil
+		return
+	}
+
+	frame := producer.queue[0]
+	producer.queue = producer.queue[1:]
+
+	producer.lastSent = time.Now()
+	producer.Network.Send(producer.CobID&0x7FF, frame)
+
+	if len(producer.queue) > 0 {
+		producer.timer = time.AfterFunc(producer.InhibitTime, producer.flush)
+	} else {
+		producer.timer = nil
+	}
+}
+
+// Stop drop the emcy messages waiting for the inhibit time
+func (producer *EmcyProducer) Stop() {
+	producer.Lock()
+	defer producer.Unlock()
+
+	if producer.timer != nil {
+		producer.timer.Stop()
+		producer.timer = nil
+	}
+	producer.queue = nil
+}
+
+// onCobIDWrite update the COB-ID on write of 0x1014
+func (producer *EmcyProducer) onCobIDWrite(index uint16, subIndex uint8, data []byte) error {
+	if len(data) != 4 {
+		return NewSDOAbortError(index, subIndex, SDOAbortTypeLength)
+	}
+
+	producer.Lock()
+	defer producer.Unlock()
+
+	producer.CobID = binary.LittleEndian.Uint32(data)
+
+	return nil
+}
+
+// setNodeID change the node id, and the COB-ID to 0x1014 or 0x80+nodeID
+func (producer *EmcyProducer) setNodeID(nodeID int) {
+	producer.Lock()
+	defer producer.Unlock()
+
+	producer.NodeID = nodeID
+	producer.CobID = uint32(0x80 + nodeID)
+
+	if producer.ObjectDic != nil {
+		producer.ObjectDic.Lock()
+		defer producer.ObjectDic.Unlock()
+
+		if object := producer.ObjectDic.FindIndex(0x1014); object != nil && len(object.GetData()) == 4 {
+			producer.CobID = binary.LittleEndian.Uint32(object.GetData())
+		}
+	}
+}
+
+// onInhibitTimeWrite update the inhibit time on write of 0x1015, in multiples of 100µs
+func (producer *EmcyProducer) onInhibitTimeWrite(index uint16, subIndex uint8, data []byte) error {
+	if len(data) != 2 {
+		return NewSDOAbortError(index, subIndex, SDOAbortTypeLength)
+	}
+
+	producer.Lock()
+	defer producer.Unlock()
+
+	producer.InhibitTime = time.Duration(binary.LittleEndian.Uint16(data)) * 100 * time.Microsecond
+
+	return nil
+}
+
+// onErrorFieldWrite clear the pre-defined error field on write of 0 to 0x1003 sub-index 0,
+// others values are refused
+func (producer *EmcyProducer) onErrorFieldWrite(index uint16, subIndex uint8, data []byte) error {
+	if len(data) != 1 || data[0] != 0 {
+		return NewSDOAbortError(index, subIndex, SDOAbortInvalidValue)
+	}
+
+	producer.Lock()
+	defer producer.Unlock()
+
+	producer.ObjectDic.Lock()
+	defer producer.ObjectDic.Unlock()
+
+	object := producer.ObjectDic.FindIndex(index)
+	for member := uint16(1); object.FindIndex(member) != nil; member++ {
+		object.FindIndex(member).SetData([]byte{0, 0, 0, 0})
+	}
+
+	return nil
+}
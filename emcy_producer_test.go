@@ -0,0 +1,141 @@
+package canopen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmcyProducer(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+	producer := localNode.EmcyProducer
+
+	eventsChan := node.EmcyConsumer.AcquireEventsChan()
+	defer node.EmcyConsumer.ReleaseEventsChan(eventsChan.chanID)
+
+	waitEmcy := func() EmcyError {
+		t.Helper()
+
+		select {
+		case emcy := <-eventsChan.C:
+			return emcy
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("no emcy received")
+		}
+
+		return EmcyError{}
+	}
+
+	assert.NoError(t, producer.Raise(0x4210, 0x08, []byte{1, 2}))
+	emcy := waitEmcy()
+	assert.Equal(t, uint16(0x4210), emcy.Code)
+	assert.Equal(t, uint8(0x09), emcy.Register)
+	assert.Equal(t, [5]byte{1, 2, 0, 0, 0}, emcy.Data)
+
+	assert.NoError(t, producer.Raise(0x8130, 0x10, nil))
+	assert.Equal(t, uint8(0x19), waitEmcy().Register)
+
+	register, err := node.SDOClient.ReadU8(0x1001, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(0x19), register)
+
+	// Newest error first
+	count, _ := node.SDOClient.ReadU8(0x1003, 0)
+	assert.Equal(t, uint8(2), count)
+	newest, _ := node.SDOClient.ReadU32(0x1003, 1)
+	assert.Equal(t, uint32(0x8130), newest)
+	oldest, _ := node.SDOClient.ReadU32(0x1003, 2)
+	assert.Equal(t, uint32(0x4210), oldest)
+
+	// No error reset while an error is active
+	assert.NoError(t, producer.Clear(0x4210))
+	assert.Equal(t, []uint16{0x8130}, producer.ActiveErrors())
+	assert.Equal(t, uint8(0x11), producer.ErrorRegister())
+
+	assert.NoError(t, producer.Clear(0x8130))
+	emcy = waitEmcy()
+	assert.True(t, emcy.IsReset())
+	assert.Equal(t, uint8(0), emcy.Register)
+	assert.Empty(t, eventsChan.C)
+
+	// Error field is limited to its size
+	for code := uint16(0x1000); code < 0x1006; code++ {
+		assert.NoError(t, producer.Raise(code, 0, nil))
+		waitEmcy()
+	}
+	count, _ = node.SDOClient.ReadU8(0x1003, 0)
+	assert.Equal(t, uint8(4), count)
+	newest, _ = node.SDOClient.ReadU32(0x1003, 1)
+	assert.Equal(t, uint32(0x1005), newest)
+	oldest, _ = node.SDOClient.ReadU32(0x1003, 4)
+	assert.Equal(t, uint32(0x1002), oldest)
+
+	assertSDOAbortCode(t, node.SDOClient.WriteU8(0x1003, 0, 1), SDOAbortInvalidValue)
+	assert.NoError(t, node.SDOClient.WriteU8(0x1003, 0, 0))
+	count, _ = node.SDOClient.ReadU8(0x1003, 0)
+	assert.Equal(t, uint8(0), count)
+	newest, _ = node.SDOClient.ReadU32(0x1003, 1)
+	assert.Equal(t, uint32(0), newest)
+
+	assert.Error(t, producer.Raise(0x0010, 0, nil))
+	assert.Error(t, producer.Raise(0x1000, 0, make([]byte, 6)))
+}
+
+func TestEmcyProducer_InhibitTime(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+	localNode.ObjectDic.AddObject(&DicVariable{Index: 0x1015, Name: "Inhibit time EMCY", DataType: Unsigned16, AccessType: "rw", Data: []byte{0, 0}})
+
+	// 50ms
+	assert.NoError(t, node.SDOClient.WriteU16(0x1015, 0, 500))
+
+	eventsChan := node.EmcyConsumer.AcquireEventsChan()
+	defer node.EmcyConsumer.ReleaseEventsChan(eventsChan.chanID)
+
+	assert.NoError(t, localNode.EmcyProducer.Raise(0x1000, 0, nil))
+	assert.NoError(t, localNode.EmcyProducer.Raise(0x2000, 0, nil))
+	assert.NoError(t, localNode.EmcyProducer.ClearAll())
+
+	var timestamps []time.Time
+	for _, code := range []uint16{0x1000, 0x2000, 0x0000} {
+		select {
+		case emcy := <-eventsChan.C:
+			assert.Equal(t, code, emcy.Code)
+			timestamps = append(timestamps, emcy.Timestamp)
+		case <-time.After(200 * time.Millisecond):
+			t.Fatal("no emcy received")
+		}
+	}
+
+	assert.GreaterOrEqual(t, timestamps[1].Sub(timestamps[0]), 40*time.Millisecond)
+	assert.GreaterOrEqual(t, timestamps[2].Sub(timestamps[1]), 40*time.Millisecond)
+}
+
+func TestEmcyProducer_ConcurrentUpload(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+	producer := localNode.EmcyProducer
+
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+
+		for code := uint16(0x1000); code < 0x1040; code++ {
+			producer.Raise(code, 0x08, nil)
+			producer.Clear(code)
+		}
+	}()
+
+	// Uploads of 0x1001 and 0x1003 while errors are raised
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+
+		_, err := node.SDOClient.ReadU8(0x1001, 0)
+		assert.NoError(t, err)
+		_, err = node.SDOClient.ReadU32(0x1003, 1)
+		assert.NoError(t, err)
+	}
+}
@@ -1,17 +1,24 @@
 package canopen
 
 import (
+	"context"
 	"time"
 
 	"github.com/jaster-prj/go-can"
 )
 
 type ISDOClient interface {
+	Abort(index uint16, subIndex uint8, code uint32) error
 	FindName(name string) DicObject
-	Read(index uint16, subIndex uint8) ([]byte, error)
+	GetOptions() SDOClientOptions
+	Read(index uint16, subIndex uint8, opts ...SDOOption) ([]byte, error)
+	ReadContext(ctx context.Context, index uint16, subIndex uint8, opts ...SDOOption) ([]byte, error)
 	Send(req []byte, expectFunc networkFramesChanFilterFunc, timeout *time.Duration, retryCount *int) (*can.Frame, error)
+	SendContext(ctx context.Context, req []byte, expectFunc networkFramesChanFilterFunc, timeout *time.Duration, retryCount *int) (*can.Frame, error)
+	SendOptions(ctx context.Context, req []byte, expectFunc networkFramesChanFilterFunc, options SDOClientOptions) (*can.Frame, error)
 	SendRequest(req []byte) error
-	Write(index uint16, subIndex uint8, forceSegment bool, data []byte) error
+	Write(index uint16, subIndex uint8, forceSegment bool, data []byte, opts ...SDOOption) error
+	WriteContext(ctx context.Context, index uint16, subIndex uint8, forceSegment bool, data []byte, opts ...SDOOption) error
 }
 
 type INode interface {
@@ -21,3 +28,9 @@ type INode interface {
 	AcquireFramesChanFromNetwork(filterFunc networkFramesChanFilterFunc) *NetworkFramesChan
 	ReleaseFramesChanFromNetwork(id string)
 }
+
+// IRemoteRequestTransport is implemented by transports able to send remote transmission requests,
+// flagged with CANRTRFlag in the arbitration id
+type IRemoteRequestTransport interface {
+	SupportsRemoteRequest() bool
+}
@@ -0,0 +1,291 @@
+package canopen
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LocalNode is a canopen node implemented by the application,
+// answering requests of others nodes on the network with its object dictionary
+type LocalNode struct {
+	// Each node has an id, which is ArbitrationID & 0x7F.
+	// It may be changed by the LSS slave once started, read it with GetId.
+	ID int
+	// idMutex guard ID against changes by the LSS slave
+	idMutex sync.Mutex
+
+	Network   *Network
+	ObjectDic *DicObjectDic
+
+	// SDOServer use the default sdo channel
+	SDOServer *SDOServer
+	// SDOServers by channel, including the default one at channel 0.
+	// Additional servers are created from parameters 0x1201-0x127F of the object dictionary.
+	SDOServers map[uint8]*SDOServer
+
+	// HeartbeatProducer send heartbeats with the producer heartbeat time 0x1017
+	HeartbeatProducer *HeartbeatProducer
+
+	// EmcyProducer send emergencies, and maintain 0x1001 and 0x1003
+	EmcyProducer *EmcyProducer
+
+	// NMTSlave apply nmt state commands to the heartbeat producer
+	NMTSlave *NMTSlave
+
+	// LSSSlave answer layer setting services, and apply the node id configured by a LSS master
+	LSSSlave *LSSSlave
+}
+
+// NewLocalNode return a new LocalNode
+func NewLocalNode(id int, network *Network, objectDic *DicObjectDic) *LocalNode {
+	return &LocalNode{
+		ID:        id,
+		Network:   network,
+		ObjectDic: objectDic,
+	}
+}
+
+// GetId returns Node ID
+func (node *LocalNode) GetId() int {
+	node.idMutex.Lock()
+	defer node.idMutex.Unlock()
+
+	return node.ID
+}
+
+// FindName gets DicObject from ObjectDic
+func (node *LocalNode) FindName(name string) DicObject {
+	if node.ObjectDic == nil {
+		return nil
+	}
+	return node.ObjectDic.FindName(name)
+}
+
+// Send sends Frame with arbitration ID by connected network
+func (node *LocalNode) Send(arbID uint32, data []byte) error {
+	if node.Network == nil {
+		return errors.New("Network not defined")
+	}
+	return node.Network.Send(arbID, data)
+}
+
+// AcquireFramesChanFromNetwork gets new Channel for given FilterFunc
+func (node *LocalNode) AcquireFramesChanFromNetwork(filterFunc networkFramesChanFilterFunc) *NetworkFramesChan {
+	if node.Network == nil {
+		return nil
+	}
+	return node.Network.AcquireFramesChan(filterFunc)
+}
+
+// ReleaseFramesChanFromNetwork free channel with given id from network
+func (node *LocalNode) ReleaseFramesChanFromNetwork(id string) {
+	if node.Network != nil {
+		node.Network.ReleaseFramesChan(id)
+	}
+}
+
+// SetNetwork set node.Network to the desired network
+func (node *LocalNode) SetNetwork(network *Network) {
+	node.Network = network
+}
+
+// SetObjectDic set node.ObjectDic to the desired ObjectDic
+func (node *LocalNode) SetObjectDic(objectDic *DicObjectDic) {
+	node.ObjectDic = objectDic
+}
+
+// Init set object dictionary values to their defaults, and create sdo server
+func (node *LocalNode) Init() {
+	if node.ObjectDic == nil {
+		node.ObjectDic = NewDicObjectDic()
+	}
+
+	for _, object := range node.ObjectDic.Indexes {
+		node.initObject(object)
+	}
+
+	node.SDOServer = NewSDOServer(node, node.ObjectDic)
+	node.SDOServers = map[uint8]*SDOServer{0: node.SDOServer}
+
+	for _, channel := range sdoChannelsFromObjectDic(node.ObjectDic, SDOServerParameterIndex) {
+		if channel.Channel == 0 {
+			continue
+		}
+		node.SDOServers[channel.Channel] = NewSDOServerWithCobIDs(node, node.ObjectDic, channel.RXCobID, channel.TXCobID)
+	}
+
+	var period time.Duration
+	if object := node.ObjectDic.FindIndex(0x1017); object != nil && len(object.GetData()) == 2 {
+		period = time.Duration(binary.LittleEndian.Uint16(object.GetData())) * time.Millisecond
+	}
+	node.HeartbeatProducer = NewHeartbeatProducer(node.ID, node.Network, period)
+	node.EmcyProducer = NewEmcyProducer(node.ID, node.Network, node.ObjectDic)
+
+	// Producers are reconfigured on writes through any sdo server
+	for _, server := range node.SDOServers {
+		server.OnWrite(0x1017, 0, node.HeartbeatProducer.onProducerHeartbeatTimeWrite)
+		server.OnWrite(0x1003, 0, node.EmcyProducer.onErrorFieldWrite)
+		server.OnWrite(0x1014, 0, node.EmcyProducer.onCobIDWrite)
+		server.OnWrite(0x1015, 0, node.EmcyProducer.onInhibitTimeWrite)
+	}
+
+	if node.NMTSlave == nil {
+		node.NMTSlave = NewNMTSlave(node)
+	}
+
+	if node.LSSSlave == nil {
+		node.LSSSlave = NewLSSSlave(node)
+	}
+}
+
+// SDOClientChannels returns the valid sdo client parameters 0x1280-0x12FF of the object dictionary,
+// to be used to address others nodes with Node.AddSDOClient
+func (node *LocalNode) SDOClientChannels() []SDOChannel {
+	return sdoChannelsFromObjectDic(node.ObjectDic, SDOClientParameterIndex)
+}
+
+// initObject set default data of object, and of its members
+func (node *LocalNode) initObject(object DicObject) {
+	switch obj := object.(type) {
+	case *DicVariable:
+		if obj.Data == nil {
+			obj.Data = obj.DefaultData(node.ID)
+		}
+	case *DicRecord:
+		for _, member := range obj.SubIndexes {
+			node.initObject(member)
+		}
+	case *DicArray:
+		for _, member := range obj.SubIndexes {
+			node.initObject(member)
+		}
+	}
+}
+
+// resetNodeIDObject set data of object, and of its members, to their default for node id
+// when it depends on the node id
+func (node *LocalNode) resetNodeIDObject(object DicObject, id int) {
+	switch obj := object.(type) {
+	case *DicVariable:
+		if strings.Contains(strings.ToLower(string(obj.Default)), "$nodeid") {
+			obj.Data = obj.DefaultData(id)
+		}
+	case *DicRecord:
+		for _, member := range obj.SubIndexes {
+			node.resetNodeIDObject(member, id)
+		}
+	case *DicArray:
+		for _, member := range obj.SubIndexes {
+			node.resetNodeIDObject(member, id)
+		}
+	}
+}
+
+// hasValidID returns true if the node id is in 1 to 127, a node without valid id
+// only answers layer setting services
+func (node *LocalNode) hasValidID() bool {
+	id := node.GetId()
+	return id >= 1 && id <= 127
+}
+
+// setNodeID reset the communication with the node id id: services are restarted on it, sending
+// the boot-up message in pre-operational state. When the node id changed, objects with a $NODEID default
+// get their default data back.
+// An id already used by another local node of the network is refused.
+func (node *LocalNode) setNodeID(id int) error {
+	oldID := node.GetId()
+
+	if node.Network != nil {
+		node.Network.Lock()
+		if other, ok := node.Network.LocalNodes[id]; ok && other != node {
+			node.Network.Unlock()
+			return fmt.Errorf("node id %d is already used by another local node", id)
+		}
+		if id != oldID && node.Network.LocalNodes[oldID] == node {
+			delete(node.Network.LocalNodes, oldID)
+			node.Network.LocalNodes[id] = node
+		}
+		node.Network.Unlock()
+	}
+
+	node.stopServices()
+
+	node.idMutex.Lock()
+	node.ID = id
+	node.idMutex.Unlock()
+
+	if id != oldID {
+		for _, object := range node.ObjectDic.Indexes {
+			node.resetNodeIDObject(object, id)
+		}
+	}
+
+	node.SDOServer.setCobIDs(uint32(0x600+id), uint32(0x580+id))
+	for _, channel := range sdoChannelsFromObjectDic(node.ObjectDic, SDOServerParameterIndex) {
+		if server, ok := node.SDOServers[channel.Channel]; ok && channel.Channel != 0 {
+			server.setCobIDs(channel.RXCobID, channel.TXCobID)
+		}
+	}
+
+	node.HeartbeatProducer.Lock()
+	node.HeartbeatProducer.NodeID = id
+	node.HeartbeatProducer.Unlock()
+	node.HeartbeatProducer.SetState(127)
+
+	node.EmcyProducer.setNodeID(id)
+
+	if !node.hasValidID() {
+		return nil
+	}
+
+	return node.startServices()
+}
+
+// Start answering requests on network
+func (node *LocalNode) Start() error {
+	if err := node.LSSSlave.Listen(); err != nil {
+		return err
+	}
+
+	if err := node.NMTSlave.Listen(); err != nil {
+		return err
+	}
+
+	if !node.hasValidID() {
+		return nil
+	}
+
+	return node.startServices()
+}
+
+// Stop answering requests on network
+func (node *LocalNode) Stop() {
+	node.LSSSlave.Unlisten()
+	node.NMTSlave.Unlisten()
+	node.stopServices()
+	node.EmcyProducer.Stop()
+}
+
+// startServices start sdo servers and the heartbeat producer, which send the boot-up message
+func (node *LocalNode) startServices() error {
+	for _, server := range node.SDOServers {
+		if err := server.Listen(); err != nil {
+			return err
+		}
+	}
+
+	return node.HeartbeatProducer.Start()
+}
+
+// stopServices stop sdo servers and the heartbeat producer
+func (node *LocalNode) stopServices() {
+	for _, server := range node.SDOServers {
+		server.Unlisten()
+	}
+
+	node.HeartbeatProducer.Stop()
+}
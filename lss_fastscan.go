@@ -0,0 +1,137 @@
+package canopen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// lssFastscanReset is the BitChecked value resetting the fastscan of slaves
+const lssFastscanReset uint8 = 0x80
+
+// LSSFastscanOptions configure Fastscan
+type LSSFastscanOptions struct {
+	// Timeout for the response of slaves to each fastscan request, 10ms by default
+	Timeout time.Duration
+	// FirstNodeID is assigned to the first node found, the next ones get consecutive node ids.
+	// With 0, node ids are not assigned and only one node is discovered, as an unconfigured node
+	// answers again to the next scan.
+	FirstNodeID uint8
+	// Store the assigned node id in each node
+	Store bool
+}
+
+// Fastscan discover unconfigured nodes, without node id, with the LSS fastscan. The identity
+// of each node found is returned, in discovery order. Nodes found are switched back to waiting mode.
+func (master *LSSMaster) Fastscan(ctx context.Context, options *LSSFastscanOptions) ([]LSSIdentity, error) {
+	if options == nil {
+		options = &LSSFastscanOptions{}
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Millisecond
+	}
+
+	if options.FirstNodeID > 127 {
+		return nil, fmt.Errorf("invalid node id %d", options.FirstNodeID)
+	}
+
+	identities := []LSSIdentity{}
+	nodeID := options.FirstNodeID
+
+	for {
+		identity, err := master.fastscanOne(ctx, timeout)
+		if err != nil {
+			return identities, err
+		}
+
+		if identity == nil {
+			return identities, nil
+		}
+
+		identities = append(identities, *identity)
+
+		if nodeID != 0 {
+			if err := master.ConfigureNodeID(ctx, nodeID); err != nil {
+				return identities, err
+			}
+
+			if options.Store {
+				if err := master.StoreConfiguration(ctx); err != nil {
+					return identities, err
+				}
+			}
+		}
+
+		if err := master.SwitchStateGlobal(LSSModeWaiting); err != nil {
+			return identities, err
+		}
+
+		if nodeID == 0 {
+			return identities, nil
+		}
+
+		if nodeID == 127 {
+			return identities, errors.New("no node id left to assign")
+		}
+		nodeID++
+	}
+}
+
+// fastscanOne find the identity of one unconfigured node, bit by bit, and switch it to
+// configuration mode. nil is returned if no unconfigured node answered.
+func (master *LSSMaster) fastscanOne(ctx context.Context, timeout time.Duration) (*LSSIdentity, error) {
+	found, err := master.fastscanRequest(ctx, timeout, 0, lssFastscanReset, 0, 0)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	values := [4]uint32{}
+
+	for sub := uint8(0); sub < 4; sub++ {
+		// Slaves answer when their bits from 31 to bit match, a bit is 1 if none answered with 0
+		for bit := 31; bit >= 0; bit-- {
+			found, err := master.fastscanRequest(ctx, timeout, values[sub], uint8(bit), sub, sub)
+			if err != nil {
+				return nil, err
+			}
+
+			if !found {
+				values[sub] |= 1 << bit
+			}
+		}
+
+		// Confirm the value, and select the next one
+		found, err := master.fastscanRequest(ctx, timeout, values[sub], 0, sub, (sub+1)%4)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			return nil, fmt.Errorf("lss fastscan value %d 0x%08X not confirmed", sub, values[sub])
+		}
+	}
+
+	return &LSSIdentity{
+		VendorID:       values[0],
+		ProductCode:    values[1],
+		RevisionNumber: values[2],
+		SerialNumber:   values[3],
+	}, nil
+}
+
+// fastscanRequest send a fastscan request, and returns true if a slave answered
+func (master *LSSMaster) fastscanRequest(ctx context.Context, timeout time.Duration, idNumber uint32, bitChecked uint8, lssSub uint8, lssNext uint8) (bool, error) {
+	data := []byte{lssFastscan, 0, 0, 0, 0, bitChecked, lssSub, lssNext}
+	binary.LittleEndian.PutUint32(data[1:5], idNumber)
+
+	_, err := master.requestTimeout(ctx, timeout, lssIdentifySlave, data)
+	if errors.Is(err, ErrLSSNoResponse) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
@@ -0,0 +1,130 @@
+package canopen
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+// fastscanSlave is a minimal unconfigured lss slave answering fastscan
+type fastscanSlave struct {
+	sync.Mutex
+
+	identity [4]uint32
+	mode     uint8
+	lssPos   uint8
+	nodeID   uint8
+}
+
+func (slave *fastscanSlave) handle(data []byte) []byte {
+	slave.Lock()
+	defer slave.Unlock()
+
+	switch data[0] {
+	case lssSwitchStateGlobal:
+		slave.mode = data[1]
+	case lssConfigureNodeID:
+		if slave.mode == LSSModeConfiguration {
+			slave.nodeID = data[1]
+			return []byte{lssConfigureNodeID, 0, 0, 0, 0, 0, 0, 0}
+		}
+	case lssFastscan:
+		if slave.mode != LSSModeWaiting || slave.nodeID != LSSNodeIDUnconfigured {
+			return nil
+		}
+
+		idNumber := uint32(data[1]) | uint32(data[2])<<8 | uint32(data[3])<<16 | uint32(data[4])<<24
+		bitChecked, lssSub, lssNext := data[5], data[6], data[7]
+
+		if bitChecked == lssFastscanReset {
+			slave.lssPos = 0
+			return []byte{lssIdentifySlave, 0, 0, 0, 0, 0, 0, 0}
+		}
+
+		if lssSub != slave.lssPos || (slave.identity[lssSub]^idNumber)>>bitChecked != 0 {
+			return nil
+		}
+
+		if bitChecked == 0 {
+			slave.lssPos = lssNext
+			if lssNext < lssSub {
+				slave.mode = LSSModeConfiguration
+			}
+		}
+
+		return []byte{lssIdentifySlave, 0, 0, 0, 0, 0, 0, 0}
+	}
+
+	return nil
+}
+
+func (slave *fastscanSlave) state() (uint8, uint8) {
+	slave.Lock()
+	defer slave.Unlock()
+
+	return slave.mode, slave.nodeID
+}
+
+func startFastscanSlaves(t *testing.T, network *Network, slaves ...*fastscanSlave) {
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == LSSMasterCobID
+	}
+	framesChan := network.AcquireFramesChan(&filterFunc)
+
+	go func() {
+		for frm := range framesChan.C {
+			for _, slave := range slaves {
+				if response := slave.handle(frm.GetData()); response != nil {
+					network.Send(LSSSlaveCobID, response)
+				}
+			}
+		}
+	}()
+}
+
+func TestLSSMaster_Fastscan(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	slaves := []*fastscanSlave{
+		{identity: [4]uint32{0x123, 0x456, 0x00010002, 1234}, nodeID: LSSNodeIDUnconfigured},
+		{identity: [4]uint32{0x123, 0x456, 0x00010002, 1233}, nodeID: LSSNodeIDUnconfigured},
+		{identity: [4]uint32{0x123, 0x456, 0x00010002, 1000}, nodeID: 3},
+	}
+	startFastscanSlaves(t, network, slaves...)
+
+	ctx := context.Background()
+	options := &LSSFastscanOptions{Timeout: 5 * time.Millisecond}
+
+	// Without node id assignment, only one node is discovered
+	identities, err := network.LSSMaster.Fastscan(ctx, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []LSSIdentity{{VendorID: 0x123, ProductCode: 0x456, RevisionNumber: 0x00010002, SerialNumber: 1233}}, identities)
+
+	options.FirstNodeID = 20
+	identities, err = network.LSSMaster.Fastscan(ctx, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []LSSIdentity{
+		{VendorID: 0x123, ProductCode: 0x456, RevisionNumber: 0x00010002, SerialNumber: 1233},
+		{VendorID: 0x123, ProductCode: 0x456, RevisionNumber: 0x00010002, SerialNumber: 1234},
+	}, identities)
+
+	mode, nodeID := slaves[0].state()
+	assert.Equal(t, LSSModeWaiting, mode)
+	assert.Equal(t, uint8(21), nodeID)
+
+	_, nodeID = slaves[1].state()
+	assert.Equal(t, uint8(20), nodeID)
+
+	// All nodes are configured
+	identities, err = network.LSSMaster.Fastscan(ctx, options)
+	assert.NoError(t, err)
+	assert.Empty(t, identities)
+
+	options.FirstNodeID = 128
+	_, err = network.LSSMaster.Fastscan(ctx, options)
+	assert.Error(t, err)
+}
@@ -0,0 +1,320 @@
+package canopen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+)
+
+// LSS COB-IDs as defined by CiA 305
+const (
+	LSSMasterCobID uint32 = 0x7E5
+	LSSSlaveCobID  uint32 = 0x7E4
+)
+
+// LSS command specifiers
+const (
+	lssSwitchStateGlobal            uint8 = 0x04
+	lssConfigureNodeID              uint8 = 0x11
+	lssConfigureBitTiming           uint8 = 0x13
+	lssActivateBitTiming            uint8 = 0x15
+	lssStoreConfiguration           uint8 = 0x17
+	lssSwitchStateSelectiveVendor   uint8 = 0x40
+	lssSwitchStateSelectiveProduct  uint8 = 0x41
+	lssSwitchStateSelectiveRevision uint8 = 0x42
+	lssSwitchStateSelectiveSerial   uint8 = 0x43
+	lssSwitchStateSelectiveResponse uint8 = 0x44
+	lssIdentifyNonConfigured        uint8 = 0x4C
+	lssIdentifySlave                uint8 = 0x4F
+	lssIdentifyNonConfiguredSlave   uint8 = 0x50
+	lssFastscan                     uint8 = 0x51
+	lssInquireVendor                uint8 = 0x5A
+	lssInquireProduct               uint8 = 0x5B
+	lssInquireRevision              uint8 = 0x5C
+	lssInquireSerial                uint8 = 0x5D
+	lssInquireNodeID                uint8 = 0x5E
+)
+
+// LSS modes
+const (
+	LSSModeWaiting       uint8 = 0
+	LSSModeConfiguration uint8 = 1
+)
+
+// LSSNodeIDUnconfigured is the node id of an unconfigured node
+const LSSNodeIDUnconfigured uint8 = 0xFF
+
+// LSS error codes of configure services
+const (
+	LSSErrorOutOfRange       uint8 = 0x01
+	LSSErrorNotSupported     uint8 = 0x01
+	LSSErrorStorageAccess    uint8 = 0x02
+	LSSErrorManufacturerCode uint8 = 0xFF
+)
+
+// LSSBitTimings map bit rates to their index in the CiA 305 bit timing table 0
+var LSSBitTimings = map[int]uint8{
+	1000000: 0,
+	800000:  1,
+	500000:  2,
+	250000:  3,
+	125000:  4,
+	50000:   6,
+	20000:   7,
+	10000:   8,
+}
+
+// ErrLSSNoResponse is returned when no LSS slave answered a request
+var ErrLSSNoResponse = errors.New("no lss response")
+
+// LSSError is returned when a LSS slave refused a configure service
+type LSSError struct {
+	// Command is the command specifier of the service
+	Command uint8
+	// Code is the error code, LSSErrorManufacturerCode for a manufacturer specific error
+	Code uint8
+	// SpecificCode is the manufacturer specific error code
+	SpecificCode uint8
+}
+
+func (e *LSSError) Error() string {
+	service := map[uint8]string{
+		lssConfigureNodeID:    "configure node-ID",
+		lssConfigureBitTiming: "configure bit timing",
+		lssStoreConfiguration: "store configuration",
+	}[e.Command]
+
+	if e.Code == LSSErrorManufacturerCode {
+		return fmt.Sprintf("lss %s failed with manufacturer specific error 0x%02X", service, e.SpecificCode)
+	}
+
+	return fmt.Sprintf("lss %s failed with error 0x%02X", service, e.Code)
+}
+
+// LSSIdentity is the LSS address of a node, its identity object 0x1018
+type LSSIdentity struct {
+	VendorID       uint32
+	ProductCode    uint32
+	RevisionNumber uint32
+	SerialNumber   uint32
+}
+
+// LSSMaster configure node ids and bit rates of nodes with the layer setting services
+type LSSMaster struct {
+	// mutex serialize requests
+	sync.Mutex
+
+	Network *Network
+	// Timeout for responses of slaves
+	Timeout time.Duration
+}
+
+// NewLSSMaster return a new LSSMaster with a response timeout of 1s
+func NewLSSMaster(network *Network) *LSSMaster {
+	return &LSSMaster{
+		Network: network,
+		Timeout: time.Second,
+	}
+}
+
+// send a request without response
+func (master *LSSMaster) send(data []byte) error {
+	if master.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	frame := make([]byte, 8)
+	copy(frame, data)
+
+	return master.Network.Send(LSSMasterCobID, frame)
+}
+
+// request send frames, and return the first response with command specifier cs
+func (master *LSSMaster) request(ctx context.Context, cs uint8, frames ...[]byte) ([]byte, error) {
+	return master.requestTimeout(ctx, master.Timeout, cs, frames...)
+}
+
+// requestTimeout send frames, and return the first response with command specifier cs received before timeout
+func (master *LSSMaster) requestTimeout(ctx context.Context, timeout time.Duration, cs uint8, frames ...[]byte) ([]byte, error) {
+	if master.Network == nil {
+		return nil, errors.New("no network defined")
+	}
+
+	master.Lock()
+	defer master.Unlock()
+
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == LSSSlaveCobID
+	}
+	framesChan := master.Network.AcquireFramesChan(&filterFunc)
+	defer master.Network.ReleaseFramesChan(framesChan.ID)
+
+	for _, data := range frames {
+		if err := master.send(data); err != nil {
+			return nil, err
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, ErrLSSNoResponse
+		case frm, ok := <-framesChan.C:
+			if !ok {
+				return nil, ErrLSSNoResponse
+			}
+			if response := frm.GetData(); len(response) == 8 && response[0] == cs {
+				return response, nil
+			}
+		}
+	}
+}
+
+// configure send a configure service, and check the error code of the response
+func (master *LSSMaster) configure(ctx context.Context, data []byte) error {
+	response, err := master.request(ctx, data[0], data)
+	if err != nil {
+		return err
+	}
+
+	if response[1] != 0 {
+		return &LSSError{Command: data[0], Code: response[1], SpecificCode: response[2]}
+	}
+
+	return nil
+}
+
+// SwitchStateGlobal switch all slaves to mode, LSSModeWaiting or LSSModeConfiguration
+func (master *LSSMaster) SwitchStateGlobal(mode uint8) error {
+	return master.send([]byte{lssSwitchStateGlobal, mode})
+}
+
+// SwitchStateSelective switch the slave with identity to configuration mode
+func (master *LSSMaster) SwitchStateSelective(ctx context.Context, identity LSSIdentity) error {
+	values := []struct {
+		cs    uint8
+		value uint32
+	}{
+		{lssSwitchStateSelectiveVendor, identity.VendorID},
+		{lssSwitchStateSelectiveProduct, identity.ProductCode},
+		{lssSwitchStateSelectiveRevision, identity.RevisionNumber},
+		{lssSwitchStateSelectiveSerial, identity.SerialNumber},
+	}
+
+	frames := [][]byte{}
+	for _, value := range values {
+		data := []byte{value.cs, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(data[1:], value.value)
+		frames = append(frames, data)
+	}
+
+	_, err := master.request(ctx, lssSwitchStateSelectiveResponse, frames...)
+	return err
+}
+
+// ConfigureNodeID set the pending node id of the slave in configuration mode, 1 to 127,
+// or LSSNodeIDUnconfigured. The node id is used after a reset communication.
+func (master *LSSMaster) ConfigureNodeID(ctx context.Context, nodeID uint8) error {
+	if (nodeID < 1 || nodeID > 127) && nodeID != LSSNodeIDUnconfigured {
+		return fmt.Errorf("invalid node id %d", nodeID)
+	}
+
+	return master.configure(ctx, []byte{lssConfigureNodeID, nodeID})
+}
+
+// ConfigureBitTiming set the pending bit timing of the slave in configuration mode,
+// with an index of the CiA 305 table 0
+func (master *LSSMaster) ConfigureBitTiming(ctx context.Context, tableIndex uint8) error {
+	return master.configure(ctx, []byte{lssConfigureBitTiming, 0, tableIndex})
+}
+
+// ConfigureBitRate set the pending bit rate of the slave in configuration mode, see LSSBitTimings
+func (master *LSSMaster) ConfigureBitRate(ctx context.Context, bitRate int) error {
+	tableIndex, ok := LSSBitTimings[bitRate]
+	if !ok {
+		return fmt.Errorf("unsupported bit rate %d", bitRate)
+	}
+
+	return master.ConfigureBitTiming(ctx, tableIndex)
+}
+
+// ActivateBitTiming ask all slaves in configuration mode to switch to their pending bit timing.
+// Slaves stop transmitting during switchDelay, then switch, then wait switchDelay again.
+func (master *LSSMaster) ActivateBitTiming(switchDelay time.Duration) error {
+	delay := switchDelay / time.Millisecond
+	if delay < 0 || delay > 0xFFFF {
+		return errors.New("switch delay out of range")
+	}
+
+	data := []byte{lssActivateBitTiming, 0, 0}
+	binary.LittleEndian.PutUint16(data[1:], uint16(delay))
+
+	return master.send(data)
+}
+
+// StoreConfiguration ask the slave in configuration mode to store its pending node id and bit timing
+func (master *LSSMaster) StoreConfiguration(ctx context.Context) error {
+	return master.configure(ctx, []byte{lssStoreConfiguration})
+}
+
+// inquireU32 inquire a value of the identity of the slave in configuration mode
+func (master *LSSMaster) inquireU32(ctx context.Context, cs uint8) (uint32, error) {
+	response, err := master.request(ctx, cs, []byte{cs})
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(response[1:5]), nil
+}
+
+// InquireIdentity returns the identity of the slave in configuration mode
+func (master *LSSMaster) InquireIdentity(ctx context.Context) (LSSIdentity, error) {
+	var identity LSSIdentity
+	var err error
+
+	if identity.VendorID, err = master.inquireU32(ctx, lssInquireVendor); err != nil {
+		return identity, err
+	}
+	if identity.ProductCode, err = master.inquireU32(ctx, lssInquireProduct); err != nil {
+		return identity, err
+	}
+	if identity.RevisionNumber, err = master.inquireU32(ctx, lssInquireRevision); err != nil {
+		return identity, err
+	}
+	if identity.SerialNumber, err = master.inquireU32(ctx, lssInquireSerial); err != nil {
+		return identity, err
+	}
+
+	return identity, nil
+}
+
+// InquireNodeID returns the active node id of the slave in configuration mode,
+// LSSNodeIDUnconfigured if it has none
+func (master *LSSMaster) InquireNodeID(ctx context.Context) (uint8, error) {
+	response, err := master.request(ctx, lssInquireNodeID, []byte{lssInquireNodeID})
+	if err != nil {
+		return 0, err
+	}
+
+	return response[1], nil
+}
+
+// IdentifyNonConfigured returns true if a slave without node id is on the network
+func (master *LSSMaster) IdentifyNonConfigured(ctx context.Context) (bool, error) {
+	_, err := master.request(ctx, lssIdentifyNonConfiguredSlave, []byte{lssIdentifyNonConfigured})
+	if errors.Is(err, ErrLSSNoResponse) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
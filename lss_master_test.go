@@ -0,0 +1,114 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+// startLSSResponder answer lss requests with responses[cs], and record requests
+func startLSSResponder(t *testing.T, network *Network, responses map[uint8][]byte) chan []byte {
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == LSSMasterCobID
+	}
+	framesChan := network.AcquireFramesChan(&filterFunc)
+	requests := make(chan []byte, 32)
+
+	go func() {
+		for frm := range framesChan.C {
+			requests <- frm.GetData()
+			if response, ok := responses[frm.Data[0]]; ok {
+				network.Send(LSSSlaveCobID, response)
+			}
+		}
+	}()
+
+	return requests
+}
+
+func TestLSSMaster(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	master := network.LSSMaster
+	master.Timeout = 50 * time.Millisecond
+
+	requests := startLSSResponder(t, network, map[uint8][]byte{
+		lssSwitchStateSelectiveSerial: {lssSwitchStateSelectiveResponse, 0, 0, 0, 0, 0, 0, 0},
+		lssConfigureNodeID:            {lssConfigureNodeID, 0, 0, 0, 0, 0, 0, 0},
+		lssConfigureBitTiming:         {lssConfigureBitTiming, LSSErrorNotSupported, 0, 0, 0, 0, 0, 0},
+		lssStoreConfiguration:         {lssStoreConfiguration, LSSErrorManufacturerCode, 0x42, 0, 0, 0, 0, 0},
+		lssInquireVendor:              {lssInquireVendor, 0x23, 0x01, 0, 0, 0, 0, 0},
+		lssInquireProduct:             {lssInquireProduct, 0x56, 0x04, 0, 0, 0, 0, 0},
+		lssInquireRevision:            {lssInquireRevision, 0x02, 0, 0x01, 0, 0, 0, 0},
+		lssInquireSerial:              {lssInquireSerial, 0xD2, 0x04, 0, 0, 0, 0, 0},
+		lssInquireNodeID:              {lssInquireNodeID, 0xFF, 0, 0, 0, 0, 0, 0},
+	})
+
+	waitRequest := func() []byte {
+		t.Helper()
+
+		select {
+		case request := <-requests:
+			return request
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("no lss request received")
+		}
+
+		return nil
+	}
+
+	ctx := context.Background()
+
+	assert.NoError(t, master.SwitchStateGlobal(LSSModeConfiguration))
+	assert.Equal(t, []byte{0x04, 0x01, 0, 0, 0, 0, 0, 0}, waitRequest())
+
+	identity := LSSIdentity{VendorID: 0x123, ProductCode: 0x456, RevisionNumber: 0x00010002, SerialNumber: 1234}
+	assert.NoError(t, master.SwitchStateSelective(ctx, identity))
+	for _, cs := range []uint8{0x40, 0x41, 0x42, 0x43} {
+		request := waitRequest()
+		assert.Equal(t, cs, request[0])
+	}
+
+	assert.NoError(t, master.ConfigureNodeID(ctx, 10))
+	assert.Equal(t, []byte{0x11, 10, 0, 0, 0, 0, 0, 0}, waitRequest())
+	assert.Error(t, master.ConfigureNodeID(ctx, 0))
+	assert.Error(t, master.ConfigureNodeID(ctx, 128))
+
+	var lssErr *LSSError
+	err := master.ConfigureBitRate(ctx, 250000)
+	if assert.ErrorAs(t, err, &lssErr) {
+		assert.Equal(t, LSSErrorNotSupported, lssErr.Code)
+		assert.Equal(t, "lss configure bit timing failed with error 0x01", lssErr.Error())
+	}
+	assert.Equal(t, []byte{0x13, 0, 3, 0, 0, 0, 0, 0}, waitRequest())
+	assert.Error(t, master.ConfigureBitRate(ctx, 42))
+
+	err = master.StoreConfiguration(ctx)
+	if assert.ErrorAs(t, err, &lssErr) {
+		assert.Equal(t, uint8(0x42), lssErr.SpecificCode)
+	}
+	waitRequest()
+
+	assert.NoError(t, master.ActivateBitTiming(100*time.Millisecond))
+	assert.Equal(t, []byte{0x15, 100, 0, 0, 0, 0, 0, 0}, waitRequest())
+
+	inquired, err := master.InquireIdentity(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, identity, inquired)
+
+	nodeID, err := master.InquireNodeID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, LSSNodeIDUnconfigured, nodeID)
+
+	// No response
+	found, err := master.IdentifyNonConfigured(ctx)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = master.IdentifyNonConfigured(canceledCtx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,376 @@
+package canopen
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+)
+
+// LSSSlave answer layer setting services of a LSS master for a local node, with the identity
+// 0x1018 of its object dictionary. The pending node id is applied on NMT reset communication,
+// or when an unconfigured node is switched back to waiting mode.
+type LSSSlave struct {
+	sync.Mutex
+
+	Node *LocalNode
+
+	// OnStore persist the pending node id and bit timing, store configuration is not supported when nil
+	OnStore func(nodeID uint8, tableIndex uint8) error
+	// OnActivateBitTiming switch the bus to the bit timing tableIndex, after switchDelay
+	OnActivateBitTiming func(tableIndex uint8, switchDelay time.Duration)
+	// OnNodeIDChange is called once a new pending node id is applied, or when a reset failed,
+	// with the error of the node if the id is refused or services failed to restart
+	OnNodeIDChange func(nodeID uint8, err error)
+
+	mode             uint8
+	pendingNodeID    uint8
+	pendingBitTiming uint8
+	selectivePos     uint8
+	lssPos           uint8
+
+	listening    bool
+	stopChan     chan bool
+	framesChanID string
+}
+
+// NewLSSSlave return a new LSSSlave for node in waiting mode, with the node id of node as pending node id
+func NewLSSSlave(node *LocalNode) *LSSSlave {
+	slave := &LSSSlave{
+		Node: node,
+		mode: LSSModeWaiting,
+	}
+	slave.pendingNodeID = slave.activeNodeID()
+
+	return slave
+}
+
+// Mode returns the lss mode, LSSModeWaiting or LSSModeConfiguration
+func (slave *LSSSlave) Mode() uint8 {
+	slave.Lock()
+	defer slave.Unlock()
+
+	return slave.mode
+}
+
+// PendingNodeID returns the node id applied on next reset communication
+func (slave *LSSSlave) PendingNodeID() uint8 {
+	slave.Lock()
+	defer slave.Unlock()
+
+	return slave.pendingNodeID
+}
+
+// PendingBitTiming returns the index of the bit timing applied on next activate bit timing
+func (slave *LSSSlave) PendingBitTiming() uint8 {
+	slave.Lock()
+	defer slave.Unlock()
+
+	return slave.pendingBitTiming
+}
+
+// Listen for lss requests and nmt commands on network
+func (slave *LSSSlave) Listen() error {
+	slave.Lock()
+	defer slave.Unlock()
+
+	if slave.Node.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	if slave.listening {
+		return nil
+	}
+
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == LSSMasterCobID || frm.ArbitrationID == 0
+	}
+
+	framesChan := slave.Node.Network.AcquireFramesChan(&filterFunc)
+	slave.framesChanID = framesChan.ID
+	slave.stopChan = make(chan bool)
+	slave.listening = true
+
+	go func(stopChan chan bool) {
+		for {
+			select {
+			case <-stopChan:
+				return
+			case frm, ok := <-framesChan.C:
+				if !ok {
+					return
+				}
+				if frm.ArbitrationID == 0 {
+					slave.handleNMT(frm.GetData())
+				} else {
+					slave.handle(frm.GetData())
+				}
+			}
+		}
+	}(slave.stopChan)
+
+	return nil
+}
+
+// Unlisten stop listening lss requests
+func (slave *LSSSlave) Unlisten() {
+	slave.Lock()
+	defer slave.Unlock()
+
+	if !slave.listening {
+		return
+	}
+
+	close(slave.stopChan)
+	slave.Node.Network.ReleaseFramesChan(slave.framesChanID)
+	slave.listening = false
+}
+
+// activeNodeID returns the node id of the node, LSSNodeIDUnconfigured if it has none
+func (slave *LSSSlave) activeNodeID() uint8 {
+	if !slave.Node.hasValidID() {
+		return LSSNodeIDUnconfigured
+	}
+
+	return uint8(slave.Node.GetId())
+}
+
+// identity returns the values of 0x1018, false if the object dictionary has no identity object
+func (slave *LSSSlave) identity() ([4]uint32, bool) {
+	var identity [4]uint32
+
+	if slave.Node.ObjectDic == nil {
+		return identity, false
+	}
+
+	object := slave.Node.ObjectDic.FindIndex(0x1018)
+	if object == nil || object.IsDicVariable() {
+		return identity, false
+	}
+
+	for subIndex := range identity {
+		member := object.FindIndex(uint16(subIndex + 1))
+		if member == nil || len(member.GetData()) != 4 {
+			return identity, false
+		}
+		identity[subIndex] = binary.LittleEndian.Uint32(member.GetData())
+	}
+
+	return identity, true
+}
+
+// respond send a response padded to 8 bytes
+func (slave *LSSSlave) respond(data ...byte) {
+	frame := make([]byte, 8)
+	copy(frame, data)
+
+	slave.Node.Send(LSSSlaveCobID, frame)
+}
+
+// handleNMT reset the communication of the node on reset node or reset communication, with the pending
+// node id. The boot-up message is sent and the state is back to pre-operational even if the node id is unchanged.
+func (slave *LSSSlave) handleNMT(data []byte) {
+	if len(data) != 2 || (data[0] != 129 && data[0] != 130) {
+		return
+	}
+
+	if data[1] != 0 && data[1] != slave.activeNodeID() {
+		return
+	}
+
+	slave.applyPendingNodeID()
+}
+
+// applyPendingNodeID reset the communication of the node with the pending node id, and notify
+// OnNodeIDChange when the node id changed or the reset failed
+func (slave *LSSSlave) applyPendingNodeID() error {
+	slave.Lock()
+	pendingNodeID := slave.pendingNodeID
+	onNodeIDChange := slave.OnNodeIDChange
+	slave.Unlock()
+
+	changed := pendingNodeID != slave.activeNodeID()
+
+	err := slave.Node.setNodeID(int(pendingNodeID))
+	if onNodeIDChange != nil && (changed || err != nil) {
+		onNodeIDChange(pendingNodeID, err)
+	}
+
+	return err
+}
+
+func (slave *LSSSlave) handle(data []byte) {
+	if len(data) != 8 {
+		return
+	}
+
+	slave.Lock()
+
+	cs := data[0]
+	mode := slave.mode
+	value := binary.LittleEndian.Uint32(data[1:5])
+
+	switch {
+	case cs == lssSwitchStateGlobal:
+		if data[1] != LSSModeWaiting && data[1] != LSSModeConfiguration {
+			break
+		}
+		slave.mode = data[1]
+		slave.selectivePos = 0
+
+		// An unconfigured node leaving configuration mode use its new node id
+		if mode == LSSModeConfiguration && data[1] == LSSModeWaiting &&
+			slave.activeNodeID() == LSSNodeIDUnconfigured && slave.pendingNodeID != LSSNodeIDUnconfigured {
+			slave.Unlock()
+			slave.applyPendingNodeID()
+			return
+		}
+
+	case cs >= lssSwitchStateSelectiveVendor && cs <= lssSwitchStateSelectiveSerial:
+		identity, ok := slave.identity()
+		pos := cs - lssSwitchStateSelectiveVendor
+
+		if !ok || pos != slave.selectivePos || identity[pos] != value {
+			slave.selectivePos = 0
+			break
+		}
+
+		slave.selectivePos++
+		if cs == lssSwitchStateSelectiveSerial {
+			slave.selectivePos = 0
+			slave.mode = LSSModeConfiguration
+			slave.Unlock()
+			slave.respond(lssSwitchStateSelectiveResponse)
+			return
+		}
+
+	case cs == lssIdentifyNonConfigured:
+		if slave.activeNodeID() == LSSNodeIDUnconfigured {
+			slave.Unlock()
+			slave.respond(lssIdentifyNonConfiguredSlave)
+			return
+		}
+
+	case cs == lssFastscan:
+		if slave.handleFastscan(data) {
+			slave.Unlock()
+			slave.respond(lssIdentifySlave)
+			return
+		}
+
+	case mode != LSSModeConfiguration:
+		// Others services are only answered in configuration mode
+
+	case cs == lssConfigureNodeID:
+		code := LSSErrorOutOfRange
+		if nodeID := data[1]; (nodeID >= 1 && nodeID <= 127) || nodeID == LSSNodeIDUnconfigured {
+			slave.pendingNodeID = nodeID
+			code = 0
+		}
+		slave.Unlock()
+		slave.respond(lssConfigureNodeID, code)
+		return
+
+	case cs == lssConfigureBitTiming:
+		code := LSSErrorNotSupported
+		if data[1] == 0 && isLSSBitTimingSupported(data[2]) {
+			slave.pendingBitTiming = data[2]
+			code = 0
+		}
+		slave.Unlock()
+		slave.respond(lssConfigureBitTiming, code)
+		return
+
+	case cs == lssActivateBitTiming:
+		onActivate := slave.OnActivateBitTiming
+		tableIndex := slave.pendingBitTiming
+		slave.Unlock()
+
+		if onActivate != nil {
+			onActivate(tableIndex, time.Duration(binary.LittleEndian.Uint16(data[1:3]))*time.Millisecond)
+		}
+		return
+
+	case cs == lssStoreConfiguration:
+		onStore := slave.OnStore
+		nodeID, tableIndex := slave.pendingNodeID, slave.pendingBitTiming
+		slave.Unlock()
+
+		if onStore == nil {
+			slave.respond(lssStoreConfiguration, LSSErrorNotSupported)
+		} else if err := onStore(nodeID, tableIndex); err != nil {
+			slave.respond(lssStoreConfiguration, LSSErrorStorageAccess)
+		} else {
+			slave.respond(lssStoreConfiguration, 0)
+		}
+		return
+
+	case cs >= lssInquireVendor && cs <= lssInquireSerial:
+		identity, ok := slave.identity()
+		slave.Unlock()
+
+		if ok {
+			response := []byte{cs, 0, 0, 0, 0}
+			binary.LittleEndian.PutUint32(response[1:], identity[cs-lssInquireVendor])
+			slave.respond(response...)
+		}
+		return
+
+	case cs == lssInquireNodeID:
+		slave.Unlock()
+		slave.respond(lssInquireNodeID, slave.activeNodeID())
+		return
+	}
+
+	slave.Unlock()
+}
+
+// handleFastscan process a fastscan request, and returns true when the node must answer it
+func (slave *LSSSlave) handleFastscan(data []byte) bool {
+	if slave.mode != LSSModeWaiting || slave.activeNodeID() != LSSNodeIDUnconfigured {
+		return false
+	}
+
+	identity, ok := slave.identity()
+	if !ok {
+		return false
+	}
+
+	idNumber := binary.LittleEndian.Uint32(data[1:5])
+	bitChecked, lssSub, lssNext := data[5], data[6], data[7]
+
+	if bitChecked == lssFastscanReset {
+		slave.lssPos = 0
+		return true
+	}
+
+	if bitChecked > 31 || lssSub > 3 || lssNext > 3 {
+		return false
+	}
+
+	if lssSub != slave.lssPos || (identity[lssSub]^idNumber)>>bitChecked != 0 {
+		return false
+	}
+
+	if bitChecked == 0 {
+		slave.lssPos = lssNext
+		if lssNext < lssSub {
+			slave.mode = LSSModeConfiguration
+		}
+	}
+
+	return true
+}
+
+// isLSSBitTimingSupported returns true if tableIndex is in LSSBitTimings
+func isLSSBitTimingSupported(tableIndex uint8) bool {
+	for _, index := range LSSBitTimings {
+		if index == tableIndex {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,226 @@
+package canopen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+// addLSSSlaveNode add an unconfigured local node with serial number serial
+func addLSSSlaveNode(t *testing.T, network *Network, serial uint32) *LocalNode {
+	dic := getLocalObjectDic()
+
+	localNode, err := network.AddLocalNode(NewLocalNode(int(LSSNodeIDUnconfigured), nil, nil), dic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, serial)
+	dic.FindIndex(0x1018).FindIndex(4).SetData(data)
+
+	return localNode
+}
+
+// acquireBootUpChan returns a chan of the boot-up and heartbeat messages of nodeID
+func acquireBootUpChan(network *Network, nodeID int) *NetworkFramesChan {
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == uint32(0x700+nodeID)
+	}
+
+	return network.AcquireFramesChan(&filterFunc)
+}
+
+func TestLSSSlave(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	master := network.LSSMaster
+	master.Timeout = 50 * time.Millisecond
+
+	localNode := addLSSSlaveNode(t, network, 1234)
+	identity := LSSIdentity{VendorID: 0x123, ProductCode: 0x456, RevisionNumber: 0x00010002, SerialNumber: 1234}
+	ctx := context.Background()
+
+	found, err := master.IdentifyNonConfigured(ctx)
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	// Configure services are ignored in waiting mode
+	assert.True(t, errors.Is(master.ConfigureNodeID(ctx, 10), ErrLSSNoResponse))
+
+	wrongIdentity := identity
+	wrongIdentity.SerialNumber = 1233
+	assert.True(t, errors.Is(master.SwitchStateSelective(ctx, wrongIdentity), ErrLSSNoResponse))
+
+	assert.NoError(t, master.SwitchStateSelective(ctx, identity))
+	assert.Equal(t, LSSModeConfiguration, localNode.LSSSlave.Mode())
+
+	inquired, err := master.InquireIdentity(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, identity, inquired)
+
+	nodeID, err := master.InquireNodeID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, LSSNodeIDUnconfigured, nodeID)
+
+	var lssErr *LSSError
+	assert.True(t, errors.As(master.configure(ctx, []byte{lssConfigureNodeID, 128}), &lssErr))
+	assert.Equal(t, LSSErrorOutOfRange, lssErr.Code)
+
+	assert.NoError(t, master.ConfigureNodeID(ctx, 10))
+	assert.Equal(t, uint8(10), localNode.LSSSlave.PendingNodeID())
+
+	assert.NoError(t, master.ConfigureBitRate(ctx, 250000))
+	assert.Equal(t, uint8(3), localNode.LSSSlave.PendingBitTiming())
+
+	assert.True(t, errors.As(master.ConfigureBitTiming(ctx, 5), &lssErr))
+	assert.Equal(t, LSSErrorNotSupported, lssErr.Code)
+
+	// Store is not supported without OnStore
+	assert.True(t, errors.As(master.StoreConfiguration(ctx), &lssErr))
+	assert.Equal(t, LSSErrorNotSupported, lssErr.Code)
+
+	stored := make(chan [2]uint8, 1)
+	changes := make(chan error, 1)
+	localNode.LSSSlave.Lock()
+	localNode.LSSSlave.OnStore = func(nodeID uint8, tableIndex uint8) error {
+		stored <- [2]uint8{nodeID, tableIndex}
+		return nil
+	}
+	localNode.LSSSlave.OnNodeIDChange = func(nodeID uint8, err error) {
+		assert.Equal(t, uint8(10), nodeID)
+		changes <- err
+	}
+	localNode.LSSSlave.Unlock()
+
+	assert.NoError(t, master.StoreConfiguration(ctx))
+	assert.Equal(t, [2]uint8{10, 3}, <-stored)
+
+	// The pending node id is applied on reset communication
+	bootUpChan := acquireBootUpChan(network, 10)
+	defer network.ReleaseFramesChan(bootUpChan.ID)
+
+	assert.NoError(t, network.SendNMTCommand(130, 0))
+	assert.Equal(t, []byte{0x00}, waitFrame(t, bootUpChan, 100*time.Millisecond).GetData())
+	assert.NoError(t, <-changes)
+
+	assert.Equal(t, 10, localNode.GetId())
+	network.Lock()
+	assert.Equal(t, localNode, network.LocalNodes[10])
+	network.Unlock()
+
+	node := network.AddNode(NewNode(10, nil, nil), getLocalObjectDic(), false)
+	setpoint, err := node.SDOClient.ReadU16(0x2000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(10+0x10), setpoint)
+
+	nodeID, err = master.InquireNodeID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(10), nodeID)
+
+	found, err = master.IdentifyNonConfigured(ctx)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestLSSSlave_Fastscan(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	network.LSSMaster.Timeout = 50 * time.Millisecond
+
+	localNode := addLSSSlaveNode(t, network, 1233)
+
+	// A configured node does not answer fastscan
+	if _, err := network.AddLocalNode(NewLocalNode(3, nil, nil), getLocalObjectDic()); err != nil {
+		t.Fatal(err)
+	}
+
+	bootUpChan := acquireBootUpChan(network, 20)
+	defer network.ReleaseFramesChan(bootUpChan.ID)
+
+	options := &LSSFastscanOptions{Timeout: 5 * time.Millisecond, FirstNodeID: 20}
+	identities, err := network.LSSMaster.Fastscan(context.Background(), options)
+	assert.NoError(t, err)
+	assert.Equal(t, []LSSIdentity{{VendorID: 0x123, ProductCode: 0x456, RevisionNumber: 0x00010002, SerialNumber: 1233}}, identities)
+
+	// The node switched back to waiting mode use its new node id
+	waitFrame(t, bootUpChan, 100*time.Millisecond)
+	assert.Equal(t, 20, localNode.GetId())
+	assert.Equal(t, LSSModeWaiting, localNode.LSSSlave.Mode())
+
+	identities, err = network.LSSMaster.Fastscan(context.Background(), options)
+	assert.NoError(t, err)
+	assert.Empty(t, identities)
+}
+
+func TestLSSSlave_NodeIDUsed(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	master := network.LSSMaster
+	master.Timeout = 50 * time.Millisecond
+
+	if _, err := network.AddLocalNode(NewLocalNode(7, nil, nil), getLocalObjectDic()); err != nil {
+		t.Fatal(err)
+	}
+
+	localNode := addLSSSlaveNode(t, network, 1233)
+	changes := make(chan error, 1)
+	localNode.LSSSlave.Lock()
+	localNode.LSSSlave.OnNodeIDChange = func(nodeID uint8, err error) {
+		changes <- err
+	}
+	localNode.LSSSlave.Unlock()
+
+	ctx := context.Background()
+	assert.NoError(t, master.SwitchStateSelective(ctx, LSSIdentity{VendorID: 0x123, ProductCode: 0x456, RevisionNumber: 0x00010002, SerialNumber: 1233}))
+	assert.NoError(t, master.ConfigureNodeID(ctx, 7))
+
+	// The node id of another local node is refused
+	assert.NoError(t, network.SendNMTCommand(130, 0))
+
+	select {
+	case err := <-changes:
+		assert.Error(t, err)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("node id change not notified")
+	}
+
+	assert.Equal(t, int(LSSNodeIDUnconfigured), localNode.GetId())
+	network.Lock()
+	assert.NotEqual(t, localNode, network.LocalNodes[7])
+	network.Unlock()
+}
+
+func TestLSSSlave_ResetUnchangedNodeID(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	bootUpChan := acquireBootUpChan(network, 5)
+	defer network.ReleaseFramesChan(bootUpChan.ID)
+
+	localNode, err := network.AddLocalNode(NewLocalNode(5, nil, nil), getLocalObjectDic())
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFrame(t, bootUpChan, 100*time.Millisecond)
+
+	changes := make(chan error, 1)
+	localNode.LSSSlave.Lock()
+	localNode.LSSSlave.OnNodeIDChange = func(nodeID uint8, err error) {
+		changes <- err
+	}
+	localNode.LSSSlave.Unlock()
+
+	for _, code := range []int{NMTCommands["RESET"], NMTCommands["RESET COMMUNICATION"]} {
+		localNode.HeartbeatProducer.SetState(5)
+
+		// The boot-up is sent and the state reset even if the node id is unchanged
+		assert.NoError(t, network.SendNMTCommand(code, 5))
+		assert.Equal(t, []byte{0x00}, waitFrame(t, bootUpChan, 100*time.Millisecond).GetData())
+		assert.Equal(t, 127, localNode.HeartbeatProducer.State())
+	}
+
+	assert.Equal(t, 5, localNode.GetId())
+	assert.Empty(t, changes)
+}
@@ -1,8 +1,11 @@
 package canopen
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"reflect"
 	"sync"
 	"time"
 
@@ -11,6 +14,12 @@ import (
 	"github.com/jaster-prj/go-canopen/utils"
 )
 
+// CANRTRFlag is set in the arbitration id of remote transmission request frames
+const CANRTRFlag uint32 = 0x40000000
+
+// ErrRemoteRequestNotSupported is returned when the transport of the bus can not send remote transmission requests
+var ErrRemoteRequestNotSupported = errors.New("remote transmission request not supported by transport")
+
 // Network represent the global nodes network
 type Network struct {
 	// mutex for FramesChans access
@@ -22,12 +31,32 @@ type Network struct {
 	// Nodes contain the network nodes
 	Nodes map[int]*Node
 
+	// LocalNodes contain the nodes implemented by the application
+	LocalNodes map[int]*LocalNode
+
 	// FramesChans contains a list of chan when is sent each frames from network bus.
 	FramesChans []*NetworkFramesChan
 
 	// NMTMaster contain nmt control struct
 	NMTMaster *NMTMaster
 
+	// HeartbeatProducer send heartbeats of the network master, started by Run.
+	// Its NodeID must be set to send heartbeats.
+	HeartbeatProducer *HeartbeatProducer
+
+	// SyncProducer send sync messages once started, it is not started by Run
+	SyncProducer *SyncProducer
+
+	// SyncConsumer monitor sync messages once listening, it is not started by Run
+	SyncConsumer *SyncConsumer
+
+	// TimeProducer and TimeConsumer send and receive time stamps, they are not started by Run
+	TimeProducer *TimeProducer
+	TimeConsumer *TimeConsumer
+
+	// LSSMaster configure node ids and bit rates of nodes
+	LSSMaster *LSSMaster
+
 	// stopChan permit to stop network
 	stopChan chan bool
 
@@ -46,6 +75,15 @@ func NewNetwork(bus can.Bus) (*Network, error) {
 	// Set nmt and listen for nmt hearbeat messages
 	netw.NMTMaster = NewNMTMaster(0, netw)
 
+	// Heartbeat producer is disabled until its node id is set
+	netw.HeartbeatProducer = NewHeartbeatProducer(0, netw, 0)
+
+	netw.SyncProducer = NewSyncProducer(netw)
+	netw.SyncConsumer = NewSyncConsumer(netw)
+	netw.TimeProducer = NewTimeProducer(netw)
+	netw.TimeConsumer = NewTimeConsumer(netw)
+	netw.LSSMaster = NewLSSMaster(netw)
+
 	return netw, nil
 }
 
@@ -82,7 +120,7 @@ func (network *Network) Run() error {
 		}
 	}()
 
-	return nil
+	return network.HeartbeatProducer.Start()
 }
 
 // Stop handlers for frames on bus
@@ -91,21 +129,66 @@ func (network *Network) Stop() error {
 		return nil
 	}
 
-	// Start network nmt master hearbeat listener
+	// Stop network nmt master hearbeat listener
 	if err := network.NMTMaster.UnlistenForHeartbeat(); err != nil {
 		return err
 	}
 
+	network.HeartbeatProducer.Stop()
+	network.SyncProducer.Stop()
+	network.SyncConsumer.Unlisten()
+	network.TimeProducer.Stop()
+	network.TimeConsumer.Unlisten()
+
 	// Stop each nodes
 	for _, node := range network.Nodes {
 		node.Stop()
 	}
 
+	// Stop each local nodes, out of the lock taken to release their frames chans
+	network.Lock()
+	localNodes := make([]*LocalNode, 0, len(network.LocalNodes))
+	for _, node := range network.LocalNodes {
+		localNodes = append(localNodes, node)
+	}
+	network.Unlock()
+
+	for _, node := range localNodes {
+		node.Stop()
+	}
+
 	network.stopChan <- true
+	network.running = false
 
 	return nil
 }
 
+// SupportsRemoteRequest returns true if the transport of the bus send frames flagged with CANRTRFlag
+// as remote transmission requests: socketcan, or a transport implementing IRemoteRequestTransport
+func (network *Network) SupportsRemoteRequest() bool {
+	transport := network.Bus.Transport
+	if rtrTransport, ok := transport.(IRemoteRequestTransport); ok {
+		return rtrTransport.SupportsRemoteRequest()
+	}
+
+	// Matched by name, the transports package does not build on every platform
+	return transport != nil && reflect.TypeOf(transport).String() == "*transports.SocketCan"
+}
+
+// SendRemoteRequest send a remote transmission request frame of dlc bytes on network.
+// The request is flagged with CANRTRFlag in the arbitration id, ErrRemoteRequestNotSupported
+// is returned on transports which would send it as a data frame.
+func (network *Network) SendRemoteRequest(arbID uint32, dlc uint8) error {
+	if !network.SupportsRemoteRequest() {
+		return ErrRemoteRequestNotSupported
+	}
+
+	return network.Bus.Write(&can.Frame{
+		ArbitrationID: arbID | CANRTRFlag,
+		DLC:           dlc,
+	})
+}
+
 // Send a frame on network
 func (network *Network) Send(arbID uint32, data []byte) error {
 	frm := &can.Frame{
@@ -157,6 +240,11 @@ func (network *Network) AddNode(node *Node, objectDic *DicObjectDic, uploadEDS b
 		log.Fatalf("Failed to start nmt master on node %d with err %v", node.ID, err)
 	}
 
+	// Start emcy consumer
+	if err := node.EmcyConsumer.Listen(); err != nil {
+		log.Fatalf("Failed to start emcy consumer on node %d with err %v", node.ID, err)
+	}
+
 	network.Lock()
 	defer network.Unlock()
 	// Initialize Nodes
@@ -170,6 +258,41 @@ func (network *Network) AddNode(node *Node, objectDic *DicObjectDic, uploadEDS b
 	return node
 }
 
+// AddLocalNode add a node implemented by the application to the network,
+// and start answering requests
+func (network *Network) AddLocalNode(node *LocalNode, objectDic *DicObjectDic) (*LocalNode, error) {
+	if node == nil {
+		return nil, errors.New("cannot use nil LocalNode")
+	}
+
+	// Set node network
+	node.SetNetwork(network)
+
+	// Set ObjectDic
+	if objectDic != nil {
+		node.SetObjectDic(objectDic)
+	}
+
+	// Init node
+	node.Init()
+
+	if err := node.Start(); err != nil {
+		return nil, err
+	}
+
+	network.Lock()
+	defer network.Unlock()
+	// Initialize LocalNodes
+	if network.LocalNodes == nil {
+		network.LocalNodes = map[int]*LocalNode{}
+	}
+
+	// Append node to network
+	network.LocalNodes[node.ID] = node
+
+	return node, nil
+}
+
 // GetNode by node id. Return error if node dont exist in network.Nodes
 func (network *Network) GetNode(nodeID int) (*Node, error) {
 	network.Lock()
@@ -192,7 +315,7 @@ func (network *Network) AcquireFramesChan(filterFunc networkFramesChanFilterFunc
 	frameChan := &NetworkFramesChan{
 		ID:     chanID,
 		Filter: filterFunc,
-		C:      make(chan *can.Frame, 10),
+		C:      make(chan *can.Frame, networkFramesChanSize),
 	}
 
 	// Append network.FramesChans
@@ -232,8 +355,24 @@ func (network *Network) ReleaseFramesChan(id string) {
 	)
 }
 
-// Search send data to network and wait for nodes response
+// Search send data to network and wait timeout for nodes response, after all nodes were pinged
 func (network *Network) Search(limit int, timeout time.Duration) ([]*Node, error) {
+	return network.search(context.Background(), limit, func() {
+		time.Sleep(timeout)
+	})
+}
+
+// SearchContext send data to network and wait for nodes response until ctx is done.
+// Nodes found are returned on ctx deadline, even when it expires while pinging, and
+// with the ctx error on cancellation.
+func (network *Network) SearchContext(ctx context.Context, limit int) ([]*Node, error) {
+	return network.search(ctx, limit, func() {
+		<-ctx.Done()
+	})
+}
+
+// search ping limit nodes, stopping early when ctx is done, then call wait and return the nodes found
+func (network *Network) search(ctx context.Context, limit int, wait func()) ([]*Node, error) {
 	if limit == 0 {
 		limit = 127
 	}
@@ -246,8 +385,11 @@ func (network *Network) Search(limit int, timeout time.Duration) ([]*Node, error
 
 	// Nodes found
 	nodes := make([]*Node, 0, limit)
+	done := make(chan bool)
 
 	go func() {
+		defer close(done)
+
 		for frm := range framesChan.C {
 			service := frm.ArbitrationID & 0x780
 			nodeID := int(frm.ArbitrationID & 0x7F)
@@ -275,21 +417,28 @@ func (network *Network) Search(limit int, timeout time.Duration) ([]*Node, error
 	}()
 
 	// Send ping for `limit` nodes
+	var err error
 	reqData := []byte{0x40, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00}
-	for i := 1; i <= limit; i++ {
-		if err := network.Send(uint32(0x600+i), reqData); err != nil {
-			return nil, err
+	for i := 1; i <= limit && ctx.Err() == nil; i++ {
+		if err = network.Send(uint32(0x600+i), reqData); err != nil {
+			break
 		}
 
 		time.Sleep(1 * time.Millisecond)
 	}
 
-	// timer := time.NewTimer(timeout)
-	time.Sleep(timeout)
+	if err == nil {
+		wait()
+
+		if errors.Is(ctx.Err(), context.Canceled) {
+			err = ctx.Err()
+		}
+	}
 
 	// Release fram chan (will stop goroutine)
 	network.ReleaseFramesChan(framesChan.ID)
+	<-done
 
 	// Return nodes
-	return nodes, nil
+	return nodes, err
 }
@@ -4,6 +4,9 @@ import (
 	"github.com/jaster-prj/go-can"
 )
 
+// networkFramesChanSize is large enough to buffer a whole sdo block (127 segments)
+const networkFramesChanSize = 128
+
 type networkFramesChanFilterFunc *(func(*can.Frame) bool)
 
 // NetworkFramesChan contain a Chan, and ID and a Filter function
@@ -0,0 +1,143 @@
+package canopen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// SendNMTCommand send the nmt command code to nodeID, 0 for all nodes
+func (network *Network) SendNMTCommand(code int, nodeID int) error {
+	return network.Send(0, []byte{uint8(code), uint8(nodeID)})
+}
+
+// BroadcastNMTCommand send the nmt command cmd, a key of NMTCommands, to all nodes
+func (network *Network) BroadcastNMTCommand(cmd string) error {
+	code, ok := NMTCommands[cmd]
+	if !ok {
+		return errors.New("invalid NMT state")
+	}
+
+	return network.SendNMTCommand(code, 0)
+}
+
+// SendNMTCommandToNodes send the nmt command cmd, a key of NMTCommands, to each node of nodeIDs
+func (network *Network) SendNMTCommandToNodes(cmd string, nodeIDs []int) error {
+	code, ok := NMTCommands[cmd]
+	if !ok {
+		return errors.New("invalid NMT state")
+	}
+
+	for _, nodeID := range nodeIDs {
+		if err := network.SendNMTCommand(code, nodeID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNodesState send the nmt command cmd, a key of NMTCommands, to each node of nodeIDs,
+// and wait each node to report the expected state by heartbeat or node guarding.
+// If nodeIDs is nil, the command is broadcast and all network nodes are verified.
+// The returned map has an entry for each node, nil if the node reached the expected state.
+func (network *Network) SetNodesState(ctx context.Context, cmd string, nodeIDs []int) (map[int]error, error) {
+	code, ok := NMTCommands[cmd]
+	if !ok {
+		return nil, errors.New("invalid NMT state")
+	}
+	state := NMTCommandToState[code]
+
+	broadcast := nodeIDs == nil
+	if broadcast {
+		nodeIDs = network.nodeIDs()
+	}
+
+	results := make(map[int]error, len(nodeIDs))
+	masters := map[int]*NMTMaster{}
+	changesChans := map[int]*NMTChangeChan{}
+
+	for _, nodeID := range nodeIDs {
+		node, err := network.GetNode(nodeID)
+		if err != nil {
+			results[nodeID] = err
+			continue
+		}
+
+		// Listen before sending the command, to not miss a boot-up
+		node.NMTMaster.resetStateReceived()
+		masters[nodeID] = node.NMTMaster
+		changesChans[nodeID] = node.NMTMaster.AcquireChangesChan()
+	}
+
+	defer func() {
+		for nodeID, changesChan := range changesChans {
+			masters[nodeID].ReleaseChangesChan(changesChan.chanID)
+		}
+	}()
+
+	var err error
+	if broadcast {
+		err = network.SendNMTCommand(code, 0)
+	} else {
+		err = network.SendNMTCommandToNodes(cmd, nodeIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for nodeID, master := range masters {
+		wg.Add(1)
+		go func(nodeID int, master *NMTMaster) {
+			defer wg.Done()
+
+			// A boot-up is expected after a reset, else the state of next heartbeat
+			err := master.waitForStateChange(ctx, changesChans[nodeID], state, state != 0)
+			if err != nil {
+				err = fmt.Errorf("node %d did not reach state %d: %w", nodeID, state, err)
+			}
+
+			mutex.Lock()
+			results[nodeID] = err
+			mutex.Unlock()
+		}(nodeID, master)
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
+
+// StartAll broadcast the OPERATIONAL command, and wait all network nodes to be operational
+func (network *Network) StartAll(ctx context.Context) (map[int]error, error) {
+	return network.SetNodesState(ctx, "OPERATIONAL", nil)
+}
+
+// StopAll broadcast the STOPPED command, and wait all network nodes to be stopped
+func (network *Network) StopAll(ctx context.Context) (map[int]error, error) {
+	return network.SetNodesState(ctx, "STOPPED", nil)
+}
+
+// ResetAll broadcast the RESET command, and wait the boot-up of all network nodes
+func (network *Network) ResetAll(ctx context.Context) (map[int]error, error) {
+	return network.SetNodesState(ctx, "RESET", nil)
+}
+
+// nodeIDs returns the sorted ids of network nodes
+func (network *Network) nodeIDs() []int {
+	network.Lock()
+	defer network.Unlock()
+
+	nodeIDs := make([]int, 0, len(network.Nodes))
+	for nodeID := range network.Nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Ints(nodeIDs)
+
+	return nodeIDs
+}
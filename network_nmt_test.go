@@ -0,0 +1,95 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+// startNMTSlave answer nmt commands to nodeID with a heartbeat of the new state
+func startNMTSlave(t *testing.T, network *Network, nodeID int) {
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == 0
+	}
+	framesChan := network.AcquireFramesChan(&filterFunc)
+
+	go func() {
+		for frm := range framesChan.C {
+			if target := int(frm.Data[1]); target != 0 && target != nodeID {
+				continue
+			}
+
+			state := NMTCommandToState[int(frm.Data[0])]
+			network.Send(uint32(0x700+nodeID), []byte{uint8(state)})
+		}
+	}()
+}
+
+func TestNetwork_SetNodesState(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	for _, nodeID := range []int{3, 4, 6} {
+		network.AddNode(NewNode(nodeID, nil, nil), NewDicObjectDic(), false)
+	}
+	startNMTSlave(t, network, 3)
+	startNMTSlave(t, network, 4)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// Node 6 does not answer
+	results, err := network.StartAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.NoError(t, results[3])
+	assert.NoError(t, results[4])
+	assert.ErrorIs(t, results[6], context.DeadlineExceeded)
+
+	node3, _ := network.GetNode(3)
+	assert.Equal(t, 5, node3.NMTMaster.GetState())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	results, err = network.SetNodesState(ctx, "STOPPED", []int{3, 8})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.NoError(t, results[3])
+	assert.Error(t, results[8])
+	assert.Equal(t, 4, node3.NMTMaster.GetState())
+
+	node4, _ := network.GetNode(4)
+	assert.Equal(t, 5, node4.NMTMaster.GetState())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	results, err = network.SetNodesState(ctx, "RESET", []int{3, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]error{3: nil, 4: nil}, results)
+	assert.Equal(t, 0, *node4.NMTMaster.GetStateReceived())
+
+	_, err = network.SetNodesState(ctx, "UNKNOWN", nil)
+	assert.Error(t, err)
+}
+
+func TestNetwork_BroadcastNMTCommand(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == 0
+	}
+	framesChan := network.AcquireFramesChan(&filterFunc)
+
+	assert.NoError(t, network.BroadcastNMTCommand("PRE-OPERATIONAL"))
+	assert.Equal(t, []byte{0x80, 0x00}, waitFrame(t, framesChan, 100*time.Millisecond).GetData())
+
+	assert.NoError(t, network.SendNMTCommandToNodes("RESET COMMUNICATION", []int{1, 2}))
+	assert.Equal(t, []byte{0x82, 0x01}, waitFrame(t, framesChan, 100*time.Millisecond).GetData())
+	assert.Equal(t, []byte{0x82, 0x02}, waitFrame(t, framesChan, 100*time.Millisecond).GetData())
+
+	assert.Error(t, network.BroadcastNMTCommand("UNKNOWN"))
+}
@@ -0,0 +1,103 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+// loopbackTransport send back each written frame, as a bus shared by all nodes
+type loopbackTransport struct {
+	readChan chan *can.Frame
+}
+
+func (t *loopbackTransport) Open() error {
+	t.readChan = make(chan *can.Frame, 1024)
+	return nil
+}
+
+func (t *loopbackTransport) Close() error {
+	return nil
+}
+
+func (t *loopbackTransport) Write(frm *can.Frame) error {
+	f := *frm
+	t.readChan <- &f
+	return nil
+}
+
+func (t *loopbackTransport) ReadChan() chan *can.Frame {
+	return t.readChan
+}
+
+func (t *loopbackTransport) SupportsRemoteRequest() bool {
+	return true
+}
+
+func getLoopbackNetwork(t *testing.T) *Network {
+	bus := can.Bus{Transport: &loopbackTransport{}}
+	if err := bus.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	network, err := NewNetwork(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := network.Run(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { network.Stop() })
+
+	return network
+}
+
+func TestNetwork_SearchContext(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	if _, err := network.AddLocalNode(NewLocalNode(5, nil, nil), getLocalObjectDic()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	nodes, err := network.SearchContext(ctx, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, nodes, 1) {
+		assert.Equal(t, 5, nodes[0].ID)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = network.SearchContext(canceled, 10)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Nodes found while pinging are returned when the deadline expires before the end of pings
+	short, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	nodes, err = network.SearchContext(short, 127)
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 1)
+}
+
+func TestNetwork_Search(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	if _, err := network.AddLocalNode(NewLocalNode(100, nil, nil), getLocalObjectDic()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The timeout starts after the pings of all nodes, which take more than 127ms
+	nodes, err := network.Search(127, 10*time.Millisecond)
+	assert.NoError(t, err)
+	if assert.Len(t, nodes, 1) {
+		assert.Equal(t, 100, nodes[0].ID)
+	}
+}
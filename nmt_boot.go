@@ -0,0 +1,411 @@
+package canopen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BootErrorCode is a CiA 302-2 boot slave error code, 'A' to 'O', or a
+// code of this package for errors the standard does not define
+type BootErrorCode byte
+
+// Boot slave error codes as defined by CiA 302-2
+const (
+	// BootErrorNotListed the node is not in the network, or not configured as slave
+	BootErrorNotListed BootErrorCode = 'A'
+	// BootErrorNoDeviceType no response on upload of the device type 0x1000
+	BootErrorNoDeviceType BootErrorCode = 'B'
+	// BootErrorDeviceType the device type 0x1000 differ from the expected one
+	BootErrorDeviceType BootErrorCode = 'C'
+	// BootErrorVendorID the vendor id 0x1018/1 differ from the expected one
+	BootErrorVendorID BootErrorCode = 'D'
+	// BootErrorHeartbeat heartbeat event of a booted node, not reported by BootSlave
+	BootErrorHeartbeat BootErrorCode = 'E'
+	// BootErrorNodeGuarding no response to node guarding requests
+	BootErrorNodeGuarding BootErrorCode = 'F'
+	// BootErrorSoftwareConfig objects for program download are not configured, not reported by BootSlave
+	BootErrorSoftwareConfig BootErrorCode = 'G'
+	// BootErrorSoftwareUpdateNotAllowed a software update is required but not allowed, not reported by BootSlave
+	BootErrorSoftwareUpdateNotAllowed BootErrorCode = 'H'
+	// BootErrorSoftwareDownload the program download failed, not reported by BootSlave
+	BootErrorSoftwareDownload BootErrorCode = 'I'
+	// BootErrorConfigurationDownload the configuration download failed
+	BootErrorConfigurationDownload BootErrorCode = 'J'
+	// BootErrorStartErrorControl heartbeat event during start of error control
+	BootErrorStartErrorControl BootErrorCode = 'K'
+	// BootErrorInitiallyOperational the node was initially operational, not reported by BootSlave
+	BootErrorInitiallyOperational BootErrorCode = 'L'
+	// BootErrorProductCode the product code 0x1018/2 differ from the expected one
+	BootErrorProductCode BootErrorCode = 'M'
+	// BootErrorRevisionNumber the revision number 0x1018/3 differ from the expected one
+	BootErrorRevisionNumber BootErrorCode = 'N'
+	// BootErrorSerialNumber the serial number 0x1018/4 differ from the expected one
+	BootErrorSerialNumber BootErrorCode = 'O'
+)
+
+// Boot slave error codes not defined by CiA 302-2
+const (
+	// BootErrorResetCommunication the reset communication command could not be sent
+	BootErrorResetCommunication BootErrorCode = 'R'
+	// BootErrorNoBootUp no boot-up message after the reset communication command
+	BootErrorNoBootUp BootErrorCode = 'U'
+)
+
+// Bits of the NMT slave assignment 0x1F81
+const (
+	SlaveAssignmentSlave     uint32 = 0x01
+	SlaveAssignmentMandatory uint32 = 0x08
+	SlaveAssignmentKeepAlive uint32 = 0x10
+)
+
+// ErrMandatorySlaveBoot is returned by BootSlaves when a mandatory slave failed to boot
+var ErrMandatorySlaveBoot = errors.New("boot of a mandatory slave failed")
+
+// BootSlaveError is the error of the boot of a slave
+type BootSlaveError struct {
+	NodeID int
+	Code   BootErrorCode
+	Err    error
+}
+
+func (e *BootSlaveError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("boot of node %d failed with error %c", e.NodeID, e.Code)
+	}
+
+	return fmt.Sprintf("boot of node %d failed with error %c: %v", e.NodeID, e.Code, e.Err)
+}
+
+func (e *BootSlaveError) Unwrap() error {
+	return e.Err
+}
+
+// SlaveConfig is the boot configuration of a slave. Expected identity values,
+// and configuration date and time, are not checked when 0.
+type SlaveConfig struct {
+	// Mandatory slaves prevent the start of others slaves when their boot failed
+	Mandatory bool
+	// KeepAlive an operational slave is not reset, nor configured
+	KeepAlive bool
+	// StartAllowed the slave is started as soon as it booted, else with others slaves by BootSlaves
+	StartAllowed bool
+
+	DeviceType     uint32
+	VendorID       uint32
+	ProductCode    uint32
+	RevisionNumber uint32
+	SerialNumber   uint32
+
+	// ConfigurationDate and ConfigurationTime are compared to the verify configuration 0x1020
+	// of the slave, Configuration is downloaded when they differ and 0x1020 updated
+	ConfigurationDate uint32
+	ConfigurationTime uint32
+	Configuration     map[ObjectAddress][]byte
+
+	// HeartbeatTimeout is the consumer heartbeat time, when 0 node guarding
+	// is used if GuardTime and LifeTimeFactor are set
+	HeartbeatTimeout time.Duration
+	GuardTime        time.Duration
+	LifeTimeFactor   uint8
+}
+
+// SlaveConfigFromObjectDic returns the boot configuration of nodeID from the object dictionary
+// of a manager: slave assignment 0x1F81, expected identity 0x1F84 to 0x1F88, expected
+// configuration date and time 0x1F26 and 0x1F27, and consumer heartbeat time 0x1016.
+// nil is returned if nodeID is not assigned as slave.
+func SlaveConfigFromObjectDic(dic *DicObjectDic, nodeID int) *SlaveConfig {
+	assignment, ok := dicArrayU32(dic, 0x1F81, nodeID)
+	if !ok || assignment&SlaveAssignmentSlave == 0 {
+		return nil
+	}
+
+	config := &SlaveConfig{
+		Mandatory:      assignment&SlaveAssignmentMandatory != 0,
+		KeepAlive:      assignment&SlaveAssignmentKeepAlive != 0,
+		StartAllowed:   true,
+		GuardTime:      time.Duration(assignment>>16) * time.Millisecond,
+		LifeTimeFactor: uint8(assignment >> 8),
+	}
+
+	config.DeviceType, _ = dicArrayU32(dic, 0x1F84, nodeID)
+	config.VendorID, _ = dicArrayU32(dic, 0x1F85, nodeID)
+	config.ProductCode, _ = dicArrayU32(dic, 0x1F86, nodeID)
+	config.RevisionNumber, _ = dicArrayU32(dic, 0x1F87, nodeID)
+	config.SerialNumber, _ = dicArrayU32(dic, 0x1F88, nodeID)
+	config.ConfigurationDate, _ = dicArrayU32(dic, 0x1F26, nodeID)
+	config.ConfigurationTime, _ = dicArrayU32(dic, 0x1F27, nodeID)
+
+	if object := dic.FindIndex(0x1016); object != nil {
+		for subIndex := 1; subIndex <= 0x7F; subIndex++ {
+			member := object.FindIndex(uint16(subIndex))
+			if member == nil || len(member.GetData()) != 4 {
+				continue
+			}
+
+			value := binary.LittleEndian.Uint32(member.GetData())
+			if int(value>>16)&0x7F == nodeID {
+				config.HeartbeatTimeout = time.Duration(value&0xFFFF) * time.Millisecond
+				break
+			}
+		}
+	}
+
+	return config
+}
+
+// dicArrayU32 returns the unsigned32 at sub-index nodeID of index
+func dicArrayU32(dic *DicObjectDic, index uint16, nodeID int) (uint32, bool) {
+	object := dic.FindIndex(index)
+	if object == nil {
+		return 0, false
+	}
+
+	member := object.FindIndex(uint16(nodeID))
+	if member == nil || len(member.GetData()) != 4 {
+		return 0, false
+	}
+
+	return binary.LittleEndian.Uint32(member.GetData()), true
+}
+
+// BootSlave run the CiA 302-2 boot slave process of nodeID: reset communication unless kept alive,
+// check identity, download configuration if needed, start error control, then start the node
+// if allowed. The returned error is a *BootSlaveError.
+func (network *Network) BootSlave(ctx context.Context, nodeID int, config *SlaveConfig) error {
+	node, err := network.GetNode(nodeID)
+	if err != nil || config == nil {
+		return &BootSlaveError{NodeID: nodeID, Code: BootErrorNotListed, Err: err}
+	}
+
+	master := node.NMTMaster
+
+	stateReceived := master.GetStateReceived()
+	keptAlive := config.KeepAlive && stateReceived != nil && *stateReceived == 5
+
+	if !keptAlive {
+		changesChan := master.AcquireChangesChan()
+		if err := master.SetState("RESET COMMUNICATION"); err != nil {
+			master.ReleaseChangesChan(changesChan.chanID)
+			return &BootSlaveError{NodeID: nodeID, Code: BootErrorResetCommunication, Err: err}
+		}
+		err := master.waitForStateChange(ctx, changesChan, 0, false)
+		master.ReleaseChangesChan(changesChan.chanID)
+
+		if err != nil {
+			return &BootSlaveError{NodeID: nodeID, Code: BootErrorNoBootUp, Err: err}
+		}
+	}
+
+	if err := node.checkIdentity(ctx, config); err != nil {
+		return err
+	}
+
+	if !keptAlive {
+		if err := node.downloadConfiguration(ctx, config); err != nil {
+			return err
+		}
+	}
+
+	if err := node.startErrorControl(ctx, config); err != nil {
+		return err
+	}
+
+	if config.StartAllowed && !keptAlive {
+		changesChan := master.AcquireChangesChan()
+		defer master.ReleaseChangesChan(changesChan.chanID)
+
+		if err := master.SetState("OPERATIONAL"); err != nil {
+			return &BootSlaveError{NodeID: nodeID, Code: BootErrorStartErrorControl, Err: err}
+		}
+
+		if err := master.waitForStateChange(ctx, changesChan, 5, true); err != nil {
+			return &BootSlaveError{NodeID: nodeID, Code: BootErrorStartErrorControl, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// BootSlaves boot each slave of configs in parallel, then start slaves not allowed to start
+// alone, unless a mandatory slave failed. The boot error of each slave is returned,
+// with ErrMandatorySlaveBoot if a mandatory slave failed.
+func (network *Network) BootSlaves(ctx context.Context, configs map[int]*SlaveConfig) (map[int]error, error) {
+	results := make(map[int]error, len(configs))
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for nodeID, config := range configs {
+		wg.Add(1)
+		go func(nodeID int, config *SlaveConfig) {
+			defer wg.Done()
+
+			err := network.BootSlave(ctx, nodeID, config)
+
+			mutex.Lock()
+			results[nodeID] = err
+			mutex.Unlock()
+		}(nodeID, config)
+	}
+
+	wg.Wait()
+
+	toStart := []int{}
+	for nodeID, config := range configs {
+		if results[nodeID] != nil {
+			if config != nil && config.Mandatory {
+				return results, ErrMandatorySlaveBoot
+			}
+			continue
+		}
+
+		if !config.StartAllowed {
+			toStart = append(toStart, nodeID)
+		}
+	}
+
+	if len(toStart) == 0 {
+		return results, nil
+	}
+
+	sort.Ints(toStart)
+	started, err := network.SetNodesState(ctx, "OPERATIONAL", toStart)
+	if err != nil {
+		return results, err
+	}
+
+	for nodeID, err := range started {
+		if err != nil {
+			results[nodeID] = &BootSlaveError{NodeID: nodeID, Code: BootErrorStartErrorControl, Err: err}
+		}
+	}
+
+	return results, nil
+}
+
+// checkIdentity compare device type 0x1000 and identity 0x1018 with the expected ones
+func (node *Node) checkIdentity(ctx context.Context, config *SlaveConfig) error {
+	deviceType, err := node.SDOClient.ReadU32Context(ctx, 0x1000, 0)
+	if err != nil {
+		return &BootSlaveError{NodeID: node.ID, Code: BootErrorNoDeviceType, Err: err}
+	}
+
+	if config.DeviceType != 0 && deviceType != config.DeviceType {
+		return &BootSlaveError{NodeID: node.ID, Code: BootErrorDeviceType, Err: fmt.Errorf("device type 0x%08X, expected 0x%08X", deviceType, config.DeviceType)}
+	}
+
+	identity := []struct {
+		subIndex uint8
+		expected uint32
+		code     BootErrorCode
+	}{
+		{1, config.VendorID, BootErrorVendorID},
+		{2, config.ProductCode, BootErrorProductCode},
+		{3, config.RevisionNumber, BootErrorRevisionNumber},
+		{4, config.SerialNumber, BootErrorSerialNumber},
+	}
+
+	for _, entry := range identity {
+		if entry.expected == 0 {
+			continue
+		}
+
+		value, err := node.SDOClient.ReadU32Context(ctx, 0x1018, entry.subIndex)
+		if err != nil {
+			return &BootSlaveError{NodeID: node.ID, Code: entry.code, Err: err}
+		}
+
+		if value != entry.expected {
+			return &BootSlaveError{NodeID: node.ID, Code: entry.code, Err: fmt.Errorf("0x1018/%d is 0x%08X, expected 0x%08X", entry.subIndex, value, entry.expected)}
+		}
+	}
+
+	return nil
+}
+
+// downloadConfiguration download the configuration, unless the verify configuration 0x1020
+// of the node match the expected configuration date and time
+func (node *Node) downloadConfiguration(ctx context.Context, config *SlaveConfig) error {
+	if len(config.Configuration) == 0 {
+		return nil
+	}
+
+	checkDate := config.ConfigurationDate != 0 || config.ConfigurationTime != 0
+
+	if checkDate {
+		configDate, dateErr := node.SDOClient.ReadU32Context(ctx, 0x1020, 1)
+		configTime, timeErr := node.SDOClient.ReadU32Context(ctx, 0x1020, 2)
+
+		if dateErr == nil && timeErr == nil && configDate == config.ConfigurationDate && configTime == config.ConfigurationTime {
+			return nil
+		}
+	}
+
+	for address, err := range node.SDOClient.WriteManyContext(ctx, config.Configuration) {
+		if err != nil {
+			return &BootSlaveError{NodeID: node.ID, Code: BootErrorConfigurationDownload, Err: fmt.Errorf("%v: %w", address, err)}
+		}
+	}
+
+	if checkDate {
+		data := make([]byte, 4)
+
+		binary.LittleEndian.PutUint32(data, config.ConfigurationDate)
+		if err := node.SDOClient.WriteContext(ctx, 0x1020, 1, false, data); err != nil {
+			return &BootSlaveError{NodeID: node.ID, Code: BootErrorConfigurationDownload, Err: err}
+		}
+
+		binary.LittleEndian.PutUint32(data, config.ConfigurationTime)
+		if err := node.SDOClient.WriteContext(ctx, 0x1020, 2, false, data); err != nil {
+			return &BootSlaveError{NodeID: node.ID, Code: BootErrorConfigurationDownload, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// startErrorControl start heartbeat monitoring or node guarding, and wait
+// the first heartbeat or guarding response
+func (node *Node) startErrorControl(ctx context.Context, config *SlaveConfig) error {
+	master := node.NMTMaster
+
+	var timeout time.Duration
+	code := BootErrorStartErrorControl
+
+	switch {
+	case config.HeartbeatTimeout > 0:
+		timeout = config.HeartbeatTimeout
+	case config.GuardTime > 0 && config.LifeTimeFactor > 0:
+		timeout = config.GuardTime * time.Duration(config.LifeTimeFactor)
+		code = BootErrorNodeGuarding
+	default:
+		return nil
+	}
+
+	changesChan := master.AcquireChangesChan()
+	defer master.ReleaseChangesChan(changesChan.chanID)
+
+	// Any state received is a change
+	master.resetStateReceived()
+
+	if config.HeartbeatTimeout > 0 {
+		master.SetHeartbeatTimeout(config.HeartbeatTimeout)
+	} else if err := master.StartNodeGuarding(config.GuardTime, config.LifeTimeFactor); err != nil {
+		return &BootSlaveError{NodeID: node.ID, Code: code, Err: err}
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case <-timeoutCtx.Done():
+		return &BootSlaveError{NodeID: node.ID, Code: code, Err: timeoutCtx.Err()}
+	case <-changesChan.C:
+		return nil
+	}
+}
@@ -0,0 +1,218 @@
+package canopen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+func getBootNodes(t *testing.T) (*LocalNode, *Node) {
+	network := getLoopbackNetwork(t)
+
+	dic := getLocalObjectDic()
+	dic.AddObject(&DicVariable{Index: 0x1017, Name: "Producer heartbeat time", DataType: Unsigned16, AccessType: "rw", Default: []byte("0")})
+
+	localNode, err := network.AddLocalNode(NewLocalNode(5, nil, nil), dic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	return localNode, node
+}
+
+func getSlaveConfig() *SlaveConfig {
+	return &SlaveConfig{
+		StartAllowed:      true,
+		DeviceType:        0x00020192,
+		VendorID:          0x123,
+		ProductCode:       0x456,
+		RevisionNumber:    0x00010002,
+		SerialNumber:      1234,
+		ConfigurationDate: 14000,
+		ConfigurationTime: 3600000,
+		Configuration: map[ObjectAddress][]byte{
+			{Index: 0x1017, SubIndex: 0}: {20, 0},
+			{Index: 0x2000, SubIndex: 0}: {0x34, 0x12},
+		},
+		HeartbeatTimeout: 100 * time.Millisecond,
+	}
+}
+
+func assertBootErrorCode(t *testing.T, err error, code BootErrorCode) {
+	t.Helper()
+
+	var bootErr *BootSlaveError
+	if assert.True(t, errors.As(err, &bootErr), "expected a BootSlaveError, got %v", err) {
+		assert.Equal(t, string(code), string(bootErr.Code))
+	}
+}
+
+func TestNetwork_BootSlave(t *testing.T) {
+	localNode, node := getBootNodes(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, localNode.Network.BootSlave(ctx, 5, getSlaveConfig()))
+	assert.Equal(t, 5, node.NMTMaster.GetState())
+	assert.Equal(t, 5, localNode.HeartbeatProducer.State())
+
+	assert.Equal(t, []byte{0x34, 0x12}, localNode.ObjectDic.FindIndex(0x2000).GetData())
+	assert.Equal(t, []byte{0xB0, 0x36, 0x00, 0x00}, localNode.ObjectDic.FindIndex(0x1020).FindIndex(1).GetData())
+
+	// Configuration is up to date, so not downloaded again
+	localNode.ObjectDic.FindIndex(0x2000).SetData([]byte{0x00, 0x00})
+	assert.NoError(t, localNode.Network.BootSlave(ctx, 5, getSlaveConfig()))
+	assert.Equal(t, []byte{0x00, 0x00}, localNode.ObjectDic.FindIndex(0x2000).GetData())
+
+	// Operational node kept alive is not reset
+	config := getSlaveConfig()
+	config.KeepAlive = true
+	config.Configuration = nil
+
+	changesChan := node.NMTMaster.AcquireChangesChan()
+	defer node.NMTMaster.ReleaseChangesChan(changesChan.chanID)
+
+	assert.NoError(t, localNode.Network.BootSlave(ctx, 5, config))
+	for len(changesChan.C) > 0 {
+		assert.NotEqual(t, 0, (<-changesChan.C).StateReceived)
+	}
+}
+
+func TestNetwork_BootSlaveErrors(t *testing.T) {
+	localNode, _ := getBootNodes(t)
+	network := localNode.Network
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	config := getSlaveConfig()
+	config.VendorID = 0x124
+	assertBootErrorCode(t, network.BootSlave(ctx, 5, config), BootErrorVendorID)
+
+	config = getSlaveConfig()
+	config.SerialNumber = 1
+	assertBootErrorCode(t, network.BootSlave(ctx, 5, config), BootErrorSerialNumber)
+
+	config = getSlaveConfig()
+	config.DeviceType = 0x191
+	assertBootErrorCode(t, network.BootSlave(ctx, 5, config), BootErrorDeviceType)
+
+	config = getSlaveConfig()
+	config.Configuration[ObjectAddress{Index: 0x1000, SubIndex: 0}] = []byte{0, 0, 0, 0}
+	assertBootErrorCode(t, network.BootSlave(ctx, 5, config), BootErrorConfigurationDownload)
+
+	// Heartbeats not enabled by configuration
+	config = getSlaveConfig()
+	config.ConfigurationDate = 1
+	delete(config.Configuration, ObjectAddress{Index: 0x1017, SubIndex: 0})
+	localNode.HeartbeatProducer.SetPeriod(0)
+	assertBootErrorCode(t, network.BootSlave(ctx, 5, config), BootErrorStartErrorControl)
+
+	assertBootErrorCode(t, network.BootSlave(ctx, 6, getSlaveConfig()), BootErrorNotListed)
+
+	// Listed node without boot-up
+	network.AddNode(NewNode(7, nil, nil), NewDicObjectDic(), false)
+	noBootUpCtx, cancelNoBootUp := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelNoBootUp()
+	assertBootErrorCode(t, network.BootSlave(noBootUpCtx, 7, getSlaveConfig()), BootErrorNoBootUp)
+}
+
+// failingTransport is a loopback transport failing to write frames
+type failingTransport struct {
+	loopbackTransport
+}
+
+func (t *failingTransport) Write(frm *can.Frame) error {
+	return errors.New("bus off")
+}
+
+func TestNetwork_BootSlaveResetError(t *testing.T) {
+	bus := can.Bus{Transport: &failingTransport{}}
+	if err := bus.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	network, err := NewNetwork(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	assertBootErrorCode(t, network.BootSlave(context.Background(), 5, getSlaveConfig()), BootErrorResetCommunication)
+}
+
+func TestNetwork_BootSlaves(t *testing.T) {
+	localNode, node := getBootNodes(t)
+	network := localNode.Network
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	config := getSlaveConfig()
+	config.StartAllowed = false
+
+	results, err := network.BootSlaves(ctx, map[int]*SlaveConfig{5: config, 6: {}})
+	assert.NoError(t, err)
+	assert.NoError(t, results[5])
+	assertBootErrorCode(t, results[6], BootErrorNotListed)
+	assert.Equal(t, 5, node.NMTMaster.GetState())
+
+	// Node 5 is not started when mandatory node 6 fails
+	network.SetNodesState(ctx, "PRE-OPERATIONAL", []int{5})
+
+	results, err = network.BootSlaves(ctx, map[int]*SlaveConfig{5: config, 6: {Mandatory: true}})
+	assert.ErrorIs(t, err, ErrMandatorySlaveBoot)
+	assert.NoError(t, results[5])
+	assert.Equal(t, 127, node.NMTMaster.GetState())
+}
+
+func TestSlaveConfigFromObjectDic(t *testing.T) {
+	u32 := func(value uint32) []byte {
+		data := make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, value)
+		return data
+	}
+
+	dic := NewDicObjectDic()
+	entries := map[uint16]uint32{
+		0x1F81: 0x00640319,
+		0x1F84: 0x00020192,
+		0x1F85: 0x123,
+		0x1F88: 1234,
+		0x1F26: 14000,
+	}
+	for index, value := range entries {
+		array := &DicArray{Index: index}
+		array.AddMember(&DicVariable{Index: index, SubIndex: 5, DataType: Unsigned32, Data: u32(value)})
+		dic.AddObject(array)
+	}
+
+	consumer := &DicArray{Index: 0x1016}
+	consumer.AddMember(&DicVariable{Index: 0x1016, SubIndex: 1, DataType: Unsigned32, Data: u32(0x00040064)})
+	consumer.AddMember(&DicVariable{Index: 0x1016, SubIndex: 2, DataType: Unsigned32, Data: u32(0x000500C8)})
+	dic.AddObject(consumer)
+
+	config := SlaveConfigFromObjectDic(dic, 5)
+	if assert.NotNil(t, config) {
+		assert.True(t, config.Mandatory)
+		assert.True(t, config.KeepAlive)
+		assert.Equal(t, 100*time.Millisecond, config.GuardTime)
+		assert.Equal(t, uint8(3), config.LifeTimeFactor)
+		assert.Equal(t, uint32(0x00020192), config.DeviceType)
+		assert.Equal(t, uint32(0x123), config.VendorID)
+		assert.Equal(t, uint32(0), config.ProductCode)
+		assert.Equal(t, uint32(1234), config.SerialNumber)
+		assert.Equal(t, uint32(14000), config.ConfigurationDate)
+		assert.Equal(t, 200*time.Millisecond, config.HeartbeatTimeout)
+	}
+
+	assert.Nil(t, SlaveConfigFromObjectDic(dic, 6))
+}
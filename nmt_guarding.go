@@ -0,0 +1,181 @@
+package canopen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+)
+
+// nmtGuardingToggleBit is the toggle bit of node guarding responses
+const nmtGuardingToggleBit uint8 = 0x80
+
+// nmtGuarding is the state of the node guarding of a node
+type nmtGuarding struct {
+	guardTime      time.Duration
+	lifeTimeFactor uint8
+
+	// toggle expected in the next response
+	toggle   uint8
+	answered bool
+	missed   int
+	lost     bool
+
+	stopChan chan bool
+}
+
+// isGuarding returns true if node guarding is started
+func (master *NMTMaster) isGuarding() bool {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	return master.guarding != nil
+}
+
+// StartNodeGuarding send a guarding request to the node every guardTime. NodeGuardingLost is sent when
+// the node does not answer during its life time, guardTime * lifeTimeFactor. Responses are received
+// by the heartbeat listener, see ListenForHeartbeat.
+//
+// Guarding requests are remote transmission requests, ErrRemoteRequestNotSupported is returned when
+// the transport of the network can not send them (see Network.SupportsRemoteRequest).
+func (master *NMTMaster) StartNodeGuarding(guardTime time.Duration, lifeTimeFactor uint8) error {
+	if master.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	if !master.Network.SupportsRemoteRequest() {
+		return ErrRemoteRequestNotSupported
+	}
+
+	if guardTime <= 0 || lifeTimeFactor == 0 {
+		return errors.New("node guarding disabled by guard time or life time factor")
+	}
+
+	master.StopNodeGuarding()
+
+	guarding := &nmtGuarding{
+		guardTime:      guardTime,
+		lifeTimeFactor: lifeTimeFactor,
+		answered:       true,
+		stopChan:       make(chan bool),
+	}
+
+	master.mutex.Lock()
+	master.guarding = guarding
+	master.mutex.Unlock()
+
+	go master.runNodeGuarding(guarding)
+
+	return nil
+}
+
+// StopNodeGuarding stop sending guarding requests
+func (master *NMTMaster) StopNodeGuarding() {
+	master.mutex.Lock()
+	guarding := master.guarding
+	master.guarding = nil
+	master.mutex.Unlock()
+
+	if guarding != nil {
+		close(guarding.stopChan)
+	}
+}
+
+func (master *NMTMaster) runNodeGuarding(guarding *nmtGuarding) {
+	ticker := time.NewTicker(guarding.guardTime)
+	defer ticker.Stop()
+
+	for {
+		master.checkGuardingResponse(guarding)
+		master.Network.SendRemoteRequest(uint32(0x700+master.NodeID), 1)
+
+		select {
+		case <-guarding.stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkGuardingResponse count the previous request as missed if not answered
+func (master *NMTMaster) checkGuardingResponse(guarding *nmtGuarding) {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	if !guarding.answered {
+		guarding.missed++
+
+		if guarding.missed >= int(guarding.lifeTimeFactor) && !guarding.lost {
+			guarding.lost = true
+			master.publishHeartbeatEvent(HeartbeatEvent{NodeID: master.NodeID, Event: NodeGuardingLost, State: master.heartbeatState, Timestamp: time.Now()})
+		}
+	}
+
+	guarding.answered = false
+}
+
+func (master *NMTMaster) handleGuardingFrame(frm *can.Frame) {
+	now := time.Now()
+	toggle := frm.Data[0] & nmtGuardingToggleBit
+	state := int(frm.Data[0] &^ nmtGuardingToggleBit)
+
+	master.mutex.Lock()
+	if guarding := master.guarding; guarding != nil {
+		guarding.answered = true
+		guarding.missed = 0
+		master.heartbeatState = state
+
+		if state == 0 {
+			// Boot-up, the next response start with toggle 0
+			guarding.toggle = 0
+		} else {
+			if toggle != guarding.toggle {
+				master.publishHeartbeatEvent(HeartbeatEvent{NodeID: master.NodeID, Event: NodeGuardingToggleError, State: state, Timestamp: now})
+			}
+			guarding.toggle = toggle ^ nmtGuardingToggleBit
+		}
+
+		if guarding.lost {
+			guarding.lost = false
+			master.publishHeartbeatEvent(HeartbeatEvent{NodeID: master.NodeID, Event: NodeGuardingRecovered, State: state, Timestamp: now})
+		}
+	}
+	master.mutex.Unlock()
+
+	master.updateState(state, now)
+}
+
+// StartNodeGuarding read the guard time 0x100C and the life time factor 0x100D of the node,
+// and start node guarding with them. Node guarding requires a transport sending remote
+// transmission requests, see NMTMaster.StartNodeGuarding.
+func (node *Node) StartNodeGuarding(ctx context.Context) error {
+	if node.SDOClient == nil || node.NMTMaster == nil {
+		return errors.New("node not initialized")
+	}
+
+	guardTime, err := node.SDOClient.ReadContext(ctx, 0x100C, 0)
+	if err != nil {
+		return err
+	}
+
+	if len(guardTime) != 2 {
+		return fmt.Errorf("%w: guard time has %d bytes", ErrSDODataLength, len(guardTime))
+	}
+
+	lifeTimeFactor, err := node.SDOClient.ReadContext(ctx, 0x100D, 0)
+	if err != nil {
+		return err
+	}
+
+	if len(lifeTimeFactor) != 1 {
+		return fmt.Errorf("%w: life time factor has %d bytes", ErrSDODataLength, len(lifeTimeFactor))
+	}
+
+	return node.NMTMaster.StartNodeGuarding(
+		time.Duration(binary.LittleEndian.Uint16(guardTime))*time.Millisecond,
+		lifeTimeFactor[0],
+	)
+}
@@ -0,0 +1,123 @@
+package canopen
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	guardingSlaveAnswer = iota
+	guardingSlaveWrongToggle
+	guardingSlaveSilent
+)
+
+// startGuardingSlave answer guarding requests of node 5 in operational state, depending on mode
+func startGuardingSlave(t *testing.T, network *Network, mode *int32) {
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == 0x705|CANRTRFlag
+	}
+	framesChan := network.AcquireFramesChan(&filterFunc)
+
+	go func() {
+		toggle := uint8(0)
+		for range framesChan.C {
+			switch atomic.LoadInt32(mode) {
+			case guardingSlaveAnswer:
+				network.Send(0x705, []byte{toggle | 0x05})
+				toggle ^= 0x80
+			case guardingSlaveWrongToggle:
+				network.Send(0x705, []byte{(toggle ^ 0x80) | 0x05})
+				atomic.StoreInt32(mode, guardingSlaveAnswer)
+			}
+		}
+	}()
+}
+
+func TestNMTMaster_NodeGuarding(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	mode := int32(guardingSlaveAnswer)
+	startGuardingSlave(t, network, &mode)
+
+	eventsChan := node.NMTMaster.AcquireHeartbeatEventsChan()
+	assert.Error(t, node.NMTMaster.StartNodeGuarding(20*time.Millisecond, 0))
+	assert.NoError(t, node.NMTMaster.StartNodeGuarding(20*time.Millisecond, 3))
+	defer node.NMTMaster.StopNodeGuarding()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, eventsChan.C)
+
+	atomic.StoreInt32(&mode, guardingSlaveWrongToggle)
+	event := waitHeartbeatEvent(t, eventsChan)
+	assert.Equal(t, NodeGuardingToggleError, event.Event)
+	assert.Equal(t, 5, event.State)
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Empty(t, eventsChan.C)
+
+	atomic.StoreInt32(&mode, guardingSlaveSilent)
+	event = waitHeartbeatEvent(t, eventsChan)
+	assert.Equal(t, NodeGuardingLost, event.Event)
+	assert.Equal(t, 5, event.NodeID)
+
+	atomic.StoreInt32(&mode, guardingSlaveAnswer)
+	event = waitHeartbeatEvent(t, eventsChan)
+	assert.Equal(t, NodeGuardingRecovered, event.Event)
+}
+
+func TestNode_StartNodeGuarding(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	dic := getLocalObjectDic()
+	dic.AddObject(&DicVariable{Index: 0x100C, Name: "Guard time", DataType: Unsigned16, AccessType: "rw", Default: []byte("0")})
+	dic.AddObject(&DicVariable{Index: 0x100D, Name: "Life time factor", DataType: Unsigned8, AccessType: "rw", Default: []byte("3")})
+	localNode, err := network.AddLocalNode(NewLocalNode(5, nil, nil), dic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	// Guard time 0 disable node guarding
+	assert.Error(t, node.StartNodeGuarding(context.Background()))
+
+	localNode.ObjectDic.FindIndex(0x100C).SetData([]byte{0x64, 0x00})
+	assert.NoError(t, node.StartNodeGuarding(context.Background()))
+	defer node.NMTMaster.StopNodeGuarding()
+
+	assert.Equal(t, 100*time.Millisecond, node.NMTMaster.guarding.guardTime)
+	assert.Equal(t, uint8(3), node.NMTMaster.guarding.lifeTimeFactor)
+}
+
+// dataFrameTransport is a loopback transport unable to send remote transmission requests
+type dataFrameTransport struct {
+	loopbackTransport
+}
+
+func (t *dataFrameTransport) SupportsRemoteRequest() bool {
+	return false
+}
+
+func TestNMTMaster_NodeGuardingNotSupported(t *testing.T) {
+	bus := can.Bus{Transport: &dataFrameTransport{}}
+	if err := bus.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	network, err := NewNetwork(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	assert.ErrorIs(t, network.SendRemoteRequest(0x705, 1), ErrRemoteRequestNotSupported)
+	assert.ErrorIs(t, node.NMTMaster.StartNodeGuarding(20*time.Millisecond, 3), ErrRemoteRequestNotSupported)
+	assert.False(t, node.NMTMaster.isGuarding())
+}
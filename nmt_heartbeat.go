@@ -0,0 +1,227 @@
+package canopen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// HeartbeatLost is sent when no heartbeat was received during the heartbeat timeout
+	HeartbeatLost = iota + 1
+	// HeartbeatRecovered is sent when a heartbeat is received after a HeartbeatLost
+	HeartbeatRecovered
+	// NodeGuardingLost is sent when the node did not answer guarding requests during its life time
+	NodeGuardingLost
+	// NodeGuardingRecovered is sent when the node answer a guarding request after a NodeGuardingLost
+	NodeGuardingRecovered
+	// NodeGuardingToggleError is sent when the toggle bit of a guarding response is wrong
+	NodeGuardingToggleError
+)
+
+// heartbeatEventsChanSize is the buffer size of heartbeat events chans
+const heartbeatEventsChanSize = 16
+
+// HeartbeatEvent is sent when heartbeats, or node guarding responses, of a node are lost or recovered
+type HeartbeatEvent struct {
+	NodeID int
+	// Event is one of HeartbeatLost, HeartbeatRecovered, NodeGuardingLost,
+	// NodeGuardingRecovered or NodeGuardingToggleError
+	Event int
+	// State is the last state received
+	State     int
+	Timestamp time.Time
+}
+
+type HeartbeatEventsChan struct {
+	chanID string
+	C      chan HeartbeatEvent
+}
+
+// SetHeartbeatTimeout set the consumer heartbeat time of the node. Monitoring starts
+// with the next heartbeat received. A timeout of 0 disable monitoring.
+func (master *NMTMaster) SetHeartbeatTimeout(timeout time.Duration) {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	master.HeartbeatTimeout = timeout
+	master.heartbeatLost = false
+
+	if master.heartbeatTimer != nil {
+		master.heartbeatTimer.Stop()
+		master.heartbeatTimer = nil
+	}
+}
+
+// HeartbeatLost returns true if heartbeats of the node are monitored and lost
+func (master *NMTMaster) HeartbeatLost() bool {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	return master.heartbeatLost
+}
+
+// monitorHeartbeat restart the heartbeat timer on heartbeat reception,
+// and send HeartbeatRecovered if heartbeats were lost
+func (master *NMTMaster) monitorHeartbeat(state int, timestamp time.Time) {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	master.heartbeatState = state
+
+	if master.HeartbeatTimeout <= 0 {
+		return
+	}
+
+	if master.heartbeatTimer != nil {
+		master.heartbeatTimer.Stop()
+	}
+
+	// A stopped timer may still call its function, so each timer has its own generation
+	master.heartbeatGeneration++
+	generation := master.heartbeatGeneration
+	master.heartbeatTimer = time.AfterFunc(master.HeartbeatTimeout, func() {
+		master.handleHeartbeatTimeout(generation)
+	})
+
+	if master.heartbeatLost {
+		master.heartbeatLost = false
+		master.publishHeartbeatEvent(HeartbeatEvent{NodeID: master.NodeID, Event: HeartbeatRecovered, State: state, Timestamp: timestamp})
+	}
+}
+
+// stopHeartbeatMonitoring stop the heartbeat timer, until next heartbeat reception
+func (master *NMTMaster) stopHeartbeatMonitoring() {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	if master.heartbeatTimer != nil {
+		master.heartbeatTimer.Stop()
+		master.heartbeatTimer = nil
+	}
+}
+
+func (master *NMTMaster) handleHeartbeatTimeout(generation uint64) {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	if master.heartbeatTimer == nil || generation != master.heartbeatGeneration || master.heartbeatLost {
+		return
+	}
+
+	master.heartbeatLost = true
+	master.publishHeartbeatEvent(HeartbeatEvent{NodeID: master.NodeID, Event: HeartbeatLost, State: master.heartbeatState, Timestamp: time.Now()})
+}
+
+// publishHeartbeatEvent to each events chans, master.mutex must be locked
+func (master *NMTMaster) publishHeartbeatEvent(event HeartbeatEvent) {
+	for _, eventsChan := range master.heartbeatEventsChans {
+		select {
+		case eventsChan.C <- event:
+		default:
+		}
+	}
+}
+
+// AcquireHeartbeatEventsChan create a new HeartbeatEventsChan
+func (master *NMTMaster) AcquireHeartbeatEventsChan() *HeartbeatEventsChan {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	eventsChan := &HeartbeatEventsChan{
+		chanID: uuid.Must(uuid.NewRandom()).String(),
+		C:      make(chan HeartbeatEvent, heartbeatEventsChanSize),
+	}
+
+	master.heartbeatEventsChans = append(master.heartbeatEventsChans, eventsChan)
+
+	return eventsChan
+}
+
+// ReleaseHeartbeatEventsChan release (close) a HeartbeatEventsChan
+func (master *NMTMaster) ReleaseHeartbeatEventsChan(id string) error {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	for idx, eventsChan := range master.heartbeatEventsChans {
+		if eventsChan.chanID == id {
+			close(eventsChan.C)
+			master.heartbeatEventsChans = append(
+				master.heartbeatEventsChans[:idx],
+				master.heartbeatEventsChans[idx+1:]...,
+			)
+			return nil
+		}
+	}
+
+	return errors.New("no HeartbeatEventsChan found with specified ID")
+}
+
+// ConfigureHeartbeatConsumer read the producer heartbeat time 0x1017 of the node,
+// and monitor heartbeats with this time plus margin as timeout
+func (node *Node) ConfigureHeartbeatConsumer(ctx context.Context, margin time.Duration) error {
+	if node.SDOClient == nil || node.NMTMaster == nil {
+		return errors.New("node not initialized")
+	}
+
+	data, err := node.SDOClient.ReadContext(ctx, 0x1017, 0)
+	if err != nil {
+		return err
+	}
+
+	if len(data) != 2 {
+		return fmt.Errorf("%w: producer heartbeat time has %d bytes", ErrSDODataLength, len(data))
+	}
+
+	period := binary.LittleEndian.Uint16(data)
+	if period == 0 {
+		return fmt.Errorf("heartbeat producer of node %d is disabled", node.ID)
+	}
+
+	node.NMTMaster.SetHeartbeatTimeout(time.Duration(period)*time.Millisecond + margin)
+
+	return nil
+}
+
+// ConfigureHeartbeatConsumers monitor heartbeats of network nodes with the
+// consumer heartbeat times 0x1016 of localNode. Nodes not in network are ignored.
+func (network *Network) ConfigureHeartbeatConsumers(localNode *LocalNode) error {
+	if localNode.ObjectDic == nil {
+		return errors.New("no object dictionary defined")
+	}
+
+	object := localNode.ObjectDic.FindIndex(0x1016)
+	if object == nil {
+		return errors.New("consumer heartbeat time 0x1016 not found")
+	}
+
+	for subIndex := 1; subIndex <= 0x7F; subIndex++ {
+		member := object.FindIndex(uint16(subIndex))
+		if member == nil {
+			continue
+		}
+
+		data := member.GetData()
+		if len(data) != 4 {
+			continue
+		}
+
+		value := binary.LittleEndian.Uint32(data)
+		nodeID := int(value>>16) & 0x7F
+		timeout := time.Duration(value&0xFFFF) * time.Millisecond
+
+		if nodeID == 0 {
+			continue
+		}
+
+		if node, err := network.GetNode(nodeID); err == nil {
+			node.NMTMaster.SetHeartbeatTimeout(timeout)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package canopen
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+// HeartbeatProducer transmit the nmt state of a node on 0x700+NodeID every Period
+type HeartbeatProducer struct {
+	sync.Mutex
+
+	NodeID  int
+	Network *Network
+
+	// Period between heartbeats, 0 disable heartbeats but not the boot-up message
+	Period time.Duration
+
+	state      int
+	running    bool
+	stopChan   chan bool
+	periodChan chan time.Duration
+}
+
+// NewHeartbeatProducer return a new HeartbeatProducer in PRE-OPERATIONAL state
+func NewHeartbeatProducer(nodeID int, network *Network, period time.Duration) *HeartbeatProducer {
+	return &HeartbeatProducer{
+		NodeID:  nodeID,
+		Network: network,
+		Period:  period,
+		state:   127,
+	}
+}
+
+// State returns the nmt state sent in heartbeats
+func (producer *HeartbeatProducer) State() int {
+	producer.Lock()
+	defer producer.Unlock()
+
+	return producer.state
+}
+
+// SetState set the nmt state sent in next heartbeats
+func (producer *HeartbeatProducer) SetState(state int) {
+	producer.Lock()
+	defer producer.Unlock()
+
+	producer.state = state
+}
+
+// SetPeriod change the period between heartbeats, 0 disable heartbeats
+func (producer *HeartbeatProducer) SetPeriod(period time.Duration) {
+	producer.Lock()
+	producer.Period = period
+	running := producer.running
+	periodChan := producer.periodChan
+	stopChan := producer.stopChan
+	producer.Unlock()
+
+	if running {
+		select {
+		case periodChan <- period:
+		case <-stopChan:
+		}
+	}
+}
+
+// Start send the boot-up message, then heartbeats every Period.
+// A producer with node id 0 send nothing.
+func (producer *HeartbeatProducer) Start() error {
+	producer.Lock()
+	defer producer.Unlock()
+
+	if producer.running || producer.NodeID == 0 {
+		return nil
+	}
+
+	if producer.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	// Boot-up message
+	if err := producer.Network.Send(uint32(0x700+producer.NodeID), []byte{0x00}); err != nil {
+		return err
+	}
+
+	producer.running = true
+	producer.stopChan = make(chan bool)
+	producer.periodChan = make(chan time.Duration)
+
+	go producer.run(producer.NodeID, producer.Period, producer.stopChan, producer.periodChan)
+
+	return nil
+}
+
+// Stop sending heartbeats
+func (producer *HeartbeatProducer) Stop() {
+	producer.Lock()
+	defer producer.Unlock()
+
+	if !producer.running {
+		return
+	}
+
+	producer.running = false
+	close(producer.stopChan)
+}
+
+func (producer *HeartbeatProducer) run(nodeID int, period time.Duration, stopChan chan bool, periodChan chan time.Duration) {
+	var ticker *time.Ticker
+	var tickChan <-chan time.Time
+
+	setPeriod := func(period time.Duration) {
+		if ticker != nil {
+			ticker.Stop()
+			ticker = nil
+			tickChan = nil
+		}
+
+		if period > 0 {
+			ticker = time.NewTicker(period)
+			tickChan = ticker.C
+		}
+	}
+	setPeriod(period)
+	defer setPeriod(0)
+
+	for {
+		select {
+		case <-stopChan:
+			return
+		case period := <-periodChan:
+			setPeriod(period)
+		case <-tickChan:
+			producer.Network.Send(uint32(0x700+nodeID), []byte{uint8(producer.State())})
+		}
+	}
+}
+
+// onProducerHeartbeatTimeWrite update the period of producer on write of 0x1017
+func (producer *HeartbeatProducer) onProducerHeartbeatTimeWrite(index uint16, subIndex uint8, data []byte) error {
+	if len(data) != 2 {
+		return NewSDOAbortError(index, subIndex, SDOAbortTypeLength)
+	}
+
+	producer.SetPeriod(time.Duration(binary.LittleEndian.Uint16(data)) * time.Millisecond)
+
+	return nil
+}
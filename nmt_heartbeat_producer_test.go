@@ -0,0 +1,136 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+func waitFrame(t *testing.T, framesChan *NetworkFramesChan, timeout time.Duration) *can.Frame {
+	t.Helper()
+
+	select {
+	case frm := <-framesChan.C:
+		return frm
+	case <-time.After(timeout):
+		t.Fatal("no frame received")
+	}
+
+	return nil
+}
+
+func TestNetwork_HeartbeatProducer(t *testing.T) {
+	bus := can.Bus{Transport: &loopbackTransport{}}
+	if err := bus.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	network, err := NewNetwork(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	network.HeartbeatProducer.NodeID = 1
+	network.HeartbeatProducer.Period = 20 * time.Millisecond
+
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == 0x701
+	}
+	framesChan := network.AcquireFramesChan(&filterFunc)
+
+	assert.NoError(t, network.Run())
+
+	// Boot-up, then heartbeats
+	assert.Equal(t, uint8(0x00), waitFrame(t, framesChan, 100*time.Millisecond).Data[0])
+	assert.Equal(t, uint8(127), waitFrame(t, framesChan, 100*time.Millisecond).Data[0])
+
+	network.HeartbeatProducer.SetState(5)
+	waitFrame(t, framesChan, 100*time.Millisecond)
+	assert.Equal(t, uint8(5), waitFrame(t, framesChan, 100*time.Millisecond).Data[0])
+
+	assert.NoError(t, network.Stop())
+	assert.False(t, network.running)
+
+	time.Sleep(40 * time.Millisecond)
+	for len(framesChan.C) > 0 {
+		<-framesChan.C
+	}
+	time.Sleep(40 * time.Millisecond)
+	assert.Empty(t, framesChan.C)
+}
+
+func TestLocalNode_HeartbeatProducer(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == 0x705
+	}
+	framesChan := network.AcquireFramesChan(&filterFunc)
+
+	dic := getLocalObjectDic()
+	dic.AddObject(&DicVariable{Index: 0x1017, Name: "Producer heartbeat time", DataType: Unsigned16, AccessType: "rw", Default: []byte("20")})
+	localNode, err := network.AddLocalNode(NewLocalNode(5, nil, nil), dic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, uint8(0x00), waitFrame(t, framesChan, 100*time.Millisecond).Data[0])
+	assert.Equal(t, uint8(127), waitFrame(t, framesChan, 100*time.Millisecond).Data[0])
+
+	// Producer heartbeat time written by sdo
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+	assert.NoError(t, node.SDOClient.WriteU16(0x1017, 0, 0))
+	assert.Equal(t, time.Duration(0), localNode.HeartbeatProducer.Period)
+
+	time.Sleep(40 * time.Millisecond)
+	for len(framesChan.C) > 0 {
+		<-framesChan.C
+	}
+	time.Sleep(40 * time.Millisecond)
+	assert.Empty(t, framesChan.C)
+
+	// Consumed by a heartbeat consumer
+	assert.NoError(t, node.SDOClient.WriteU16(0x1017, 0, 10))
+	assert.NoError(t, node.ConfigureHeartbeatConsumer(context.Background(), 20*time.Millisecond))
+	eventsChan := node.NMTMaster.AcquireHeartbeatEventsChan()
+
+	// Monitoring starts with the next heartbeat
+	waitFrame(t, framesChan, 100*time.Millisecond)
+	waitFrame(t, framesChan, 100*time.Millisecond)
+
+	localNode.Stop()
+	event := waitHeartbeatEvent(t, eventsChan)
+	assert.Equal(t, HeartbeatLost, event.Event)
+}
+
+func TestLocalNode_NMTCommands(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	localNode, err := network.AddLocalNode(NewLocalNode(5, nil, nil), getLocalObjectDic())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitState := func(state int) {
+		t.Helper()
+		assert.Eventually(t, func() bool {
+			return localNode.HeartbeatProducer.State() == state
+		}, 100*time.Millisecond, time.Millisecond)
+	}
+
+	assert.NoError(t, network.SendNMTCommand(NMTCommands["OPERATIONAL"], 5))
+	waitState(5)
+
+	// Commands for others nodes are ignored
+	assert.NoError(t, network.SendNMTCommand(NMTCommands["STOPPED"], 6))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 5, localNode.HeartbeatProducer.State())
+
+	assert.NoError(t, network.SendNMTCommand(NMTCommands["PRE-OPERATIONAL"], 5))
+	waitState(127)
+
+	assert.NoError(t, network.BroadcastNMTCommand("STOPPED"))
+	waitState(4)
+}
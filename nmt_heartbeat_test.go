@@ -0,0 +1,101 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waitHeartbeatEvent(t *testing.T, eventsChan *HeartbeatEventsChan) HeartbeatEvent {
+	t.Helper()
+
+	select {
+	case event := <-eventsChan.C:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no heartbeat event received")
+	}
+
+	return HeartbeatEvent{}
+}
+
+func TestNMTMaster_HeartbeatConsumer(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	node.NMTMaster.SetHeartbeatTimeout(50 * time.Millisecond)
+	eventsChan := node.NMTMaster.AcquireHeartbeatEventsChan()
+	defer node.NMTMaster.ReleaseHeartbeatEventsChan(eventsChan.chanID)
+
+	// Monitoring starts with the first heartbeat
+	time.Sleep(80 * time.Millisecond)
+	assert.Empty(t, eventsChan.C)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, network.Send(0x705, []byte{0x05}))
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Empty(t, eventsChan.C)
+	assert.False(t, node.NMTMaster.HeartbeatLost())
+
+	event := waitHeartbeatEvent(t, eventsChan)
+	assert.Equal(t, HeartbeatLost, event.Event)
+	assert.Equal(t, 5, event.NodeID)
+	assert.Equal(t, 5, event.State)
+	assert.True(t, node.NMTMaster.HeartbeatLost())
+
+	// Lost is sent once
+	time.Sleep(80 * time.Millisecond)
+	assert.Empty(t, eventsChan.C)
+
+	assert.NoError(t, network.Send(0x705, []byte{0x7F}))
+	event = waitHeartbeatEvent(t, eventsChan)
+	assert.Equal(t, HeartbeatRecovered, event.Event)
+	assert.Equal(t, 127, event.State)
+	assert.False(t, node.NMTMaster.HeartbeatLost())
+
+	// Disable monitoring
+	node.NMTMaster.SetHeartbeatTimeout(0)
+	time.Sleep(80 * time.Millisecond)
+	assert.Empty(t, eventsChan.C)
+}
+
+func TestNode_ConfigureHeartbeatConsumer(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	dic := getLocalObjectDic()
+	dic.AddObject(&DicVariable{Index: 0x1017, Name: "Producer heartbeat time", DataType: Unsigned16, AccessType: "rw", Default: []byte("100")})
+	if _, err := network.AddLocalNode(NewLocalNode(5, nil, nil), dic); err != nil {
+		t.Fatal(err)
+	}
+
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+	assert.NoError(t, node.ConfigureHeartbeatConsumer(context.Background(), 50*time.Millisecond))
+	assert.Equal(t, 150*time.Millisecond, node.NMTMaster.HeartbeatTimeout)
+}
+
+func TestNetwork_ConfigureHeartbeatConsumers(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	node3 := network.AddNode(NewNode(3, nil, nil), NewDicObjectDic(), false)
+	node4 := network.AddNode(NewNode(4, nil, nil), NewDicObjectDic(), false)
+
+	array := &DicArray{Index: 0x1016, Name: "Consumer heartbeat time"}
+	array.AddMember(&DicVariable{Index: 0x1016, SubIndex: 0, Name: "Highest sub-index supported", DataType: Unsigned8, AccessType: "const", Default: []byte("3")})
+	array.AddMember(&DicVariable{Index: 0x1016, SubIndex: 1, Name: "Consumer heartbeat time 1", DataType: Unsigned32, AccessType: "rw", Default: []byte("0x000300C8")})
+	array.AddMember(&DicVariable{Index: 0x1016, SubIndex: 2, Name: "Consumer heartbeat time 2", DataType: Unsigned32, AccessType: "rw", Default: []byte("0x00090064")})
+	array.AddMember(&DicVariable{Index: 0x1016, SubIndex: 3, Name: "Consumer heartbeat time 3", DataType: Unsigned32, AccessType: "rw", Default: []byte("0")})
+
+	dic := NewDicObjectDic()
+	dic.AddObject(array)
+
+	localNode, err := network.AddLocalNode(NewLocalNode(1, nil, nil), dic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, network.ConfigureHeartbeatConsumers(localNode))
+	assert.Equal(t, 200*time.Millisecond, node3.NMTMaster.HeartbeatTimeout)
+	assert.Equal(t, time.Duration(0), node4.NMTMaster.HeartbeatTimeout)
+}
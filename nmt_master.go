@@ -1,7 +1,9 @@
 package canopen
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -38,10 +40,15 @@ var NMTCommandToState = map[int]int{
 	130: 0,
 }
 
+// nmtChangeChanSize is the buffer size of NMTChangeChan
+const nmtChangeChanSize = 16
+
 type NMTState struct {
-	NodeID    int
-	State     int
-	Timestamp *time.Time
+	NodeID int
+	State  int
+	// StateReceived is the state sent by the node, 0 for a boot-up
+	StateReceived int
+	Timestamp     *time.Time
 }
 
 type NMTChangeChan struct {
@@ -50,16 +57,34 @@ type NMTChangeChan struct {
 }
 
 type NMTMaster struct {
-	NodeID        int
-	Network       *Network
+	NodeID  int
+	Network *Network
+
+	// State, StateReceived and Timestamp are updated by the heartbeat listener,
+	// use GetState, GetStateReceived and GetTimestamp to read them
 	State         int
 	StateReceived *int
 	Timestamp     *time.Time
-	Listening     bool
-	stopChan      chan bool
+
+	Listening bool
+	stopChan  chan bool
 
 	ChangeChans []*NMTChangeChan
 
+	// HeartbeatTimeout is the consumer heartbeat time, see SetHeartbeatTimeout
+	HeartbeatTimeout time.Duration
+
+	// mutex for state, change chans and heartbeat monitoring
+	mutex                sync.Mutex
+	heartbeatTimer       *time.Timer
+	heartbeatGeneration  uint64
+	heartbeatLost        bool
+	heartbeatState       int
+	heartbeatEventsChans []*HeartbeatEventsChan
+
+	// node guarding, see StartNodeGuarding
+	guarding *nmtGuarding
+
 	// networkFramesChanID is used to store and later close the network frames channel
 	networkFramesChanID *string
 }
@@ -93,6 +118,9 @@ func (master *NMTMaster) UnlistenForHeartbeat() error {
 
 	master.Listening = false
 
+	master.stopHeartbeatMonitoring()
+	master.StopNodeGuarding()
+
 	return nil
 }
 
@@ -108,6 +136,7 @@ func (master *NMTMaster) ListenForHeartbeat() error {
 	}
 
 	master.Listening = true
+	master.stopChan = make(chan bool, 1)
 
 	// Hearbeat message arbID
 	eventName := 0x700 + master.NodeID
@@ -122,27 +151,45 @@ func (master *NMTMaster) ListenForHeartbeat() error {
 	master.networkFramesChanID = &framesChan.ID
 
 	// Listen for messages
-	go func() {
+	go func(stopChan chan bool) {
 		for {
 			select {
-			case <-master.stopChan:
+			case <-stopChan:
 				// Stop goroutine
 				return
-			case frm := <-framesChan.C:
+			case frm, ok := <-framesChan.C:
+				if !ok {
+					return
+				}
 				master.handleHeartbeatFrame(frm)
 			}
 		}
-	}()
+	}(master.stopChan)
 
 	return nil
 }
 
 func (master *NMTMaster) handleHeartbeatFrame(frm *can.Frame) {
+	// Node guarding responses have a toggle bit
+	if master.isGuarding() {
+		master.handleGuardingFrame(frm)
+		return
+	}
+
 	now := time.Now()
+	state := master.updateState(int(frm.Data[0]), now)
+	master.monitorHeartbeat(state, now)
+}
+
+// updateState set the state received from the node, and notify changes of the state received.
+// A boot-up is always notified. The state of the node is returned.
+func (master *NMTMaster) updateState(newState int, now time.Time) int {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
 	master.Timestamp = &now
 
-	newState := int(frm.Data[0])
-	changed := int(master.State) != int(newState) || master.StateReceived == nil
+	changed := master.StateReceived == nil || *master.StateReceived != newState || newState == 0
 
 	master.StateReceived = &newState
 
@@ -153,19 +200,57 @@ func (master *NMTMaster) handleHeartbeatFrame(frm *can.Frame) {
 	}
 
 	if changed {
+		state := NMTState{NodeID: master.NodeID, State: master.State, StateReceived: newState, Timestamp: master.Timestamp}
+
 		for _, changeChan := range master.ChangeChans {
 			select {
-			case changeChan.C <- NMTState{NodeID: master.NodeID, State: master.State, Timestamp: master.Timestamp}:
+			case changeChan.C <- state:
 			default:
 			}
 		}
 	}
+
+	return master.State
+}
+
+// GetState returns the current state of the node, PRE-OPERATIONAL after a boot-up
+func (master *NMTMaster) GetState() int {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	return master.State
+}
+
+// GetStateReceived returns the last state sent by the node, nil if none
+// was received since the last command
+func (master *NMTMaster) GetStateReceived() *int {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	if master.StateReceived == nil {
+		return nil
+	}
+
+	stateReceived := *master.StateReceived
+	return &stateReceived
+}
+
+// GetTimestamp returns the reception time of the last state, nil if none
+func (master *NMTMaster) GetTimestamp() *time.Time {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	if master.Timestamp == nil {
+		return nil
+	}
+
+	timestamp := *master.Timestamp
+	return &timestamp
 }
 
 // SendCommand to target node
 func (master *NMTMaster) SendCommand(code int) error {
-	data := []byte{uint8(code), uint8(master.NodeID)}
-	return master.Network.Send(0, data)
+	return master.Network.SendNMTCommand(code, master.NodeID)
 }
 
 // SetState for target node, and send command
@@ -175,21 +260,29 @@ func (master *NMTMaster) SetState(cmd string) error {
 	}
 
 	code := NMTCommands[cmd]
-	master.StateReceived = nil
+	master.resetStateReceived()
 
 	return master.SendCommand(code)
 }
 
+// resetStateReceived forget the last state received, before a command
+func (master *NMTMaster) resetStateReceived() {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
+	master.StateReceived = nil
+}
+
 // GetStateString for target node
 func (master *NMTMaster) GetStateString() string {
-	if s, ok := NMTStates[master.State]; ok {
+	if s, ok := NMTStates[master.GetState()]; ok {
 		return s
 	}
 
 	return ""
 }
 
-// WaitForBootup return when the node has *StateReceived == 0
+// WaitForBootup return when the node send a boot-up message,
 // with a default timeout of 10s
 func (master *NMTMaster) WaitForBootup(timeout *time.Duration) error {
 	if timeout == nil {
@@ -197,23 +290,52 @@ func (master *NMTMaster) WaitForBootup(timeout *time.Duration) error {
 		timeout = &tmeout
 	}
 
-	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
 
-	for {
-		if time.Since(start) > *timeout {
-			return errors.New("timeout execeded")
+	return master.WaitForBootupContext(ctx)
+}
+
+// WaitForBootupContext return as soon as the node send a boot-up message,
+// or ctx error when ctx is done. A boot-up received before the call is ignored.
+func (master *NMTMaster) WaitForBootupContext(ctx context.Context) error {
+	return master.waitForState(ctx, 0, false)
+}
+
+// WaitForState return as soon as the node has *StateReceived == state,
+// or ctx error when ctx is done
+func (master *NMTMaster) WaitForState(ctx context.Context, state int) error {
+	return master.waitForState(ctx, state, true)
+}
+
+// waitForState wait a change to state, if current is true the state
+// already received satisfy the wait
+func (master *NMTMaster) waitForState(ctx context.Context, state int, current bool) error {
+	changesChan := master.AcquireChangesChan()
+	defer master.ReleaseChangesChan(changesChan.chanID)
+
+	return master.waitForStateChange(ctx, changesChan, state, current)
+}
+
+// waitForStateChange wait a change to state on changesChan, if current is true the state
+// already received satisfy the wait
+func (master *NMTMaster) waitForStateChange(ctx context.Context, changesChan *NMTChangeChan, state int, current bool) error {
+	if current {
+		if stateReceived := master.GetStateReceived(); stateReceived != nil && *stateReceived == state {
+			return nil
 		}
+	}
 
-		if master.StateReceived != nil {
-			if *master.StateReceived == 5 {
-				break
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case change := <-changesChan.C:
+			if change.StateReceived == state {
+				return nil
 			}
 		}
-
-		time.Sleep(time.Millisecond * 100)
 	}
-
-	return nil
 }
 
 // AcquireChangesChan create a new NMTChangeChan
@@ -222,9 +344,12 @@ func (master *NMTMaster) AcquireChangesChan() *NMTChangeChan {
 	chanID := uuid.Must(uuid.NewRandom()).String()
 	changesChan := &NMTChangeChan{
 		chanID: chanID,
-		C:      make(chan NMTState),
+		C:      make(chan NMTState, nmtChangeChanSize),
 	}
 
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
 	// Append m.ChangeChans
 	master.ChangeChans = append(master.ChangeChans, changesChan)
 
@@ -233,6 +358,9 @@ func (master *NMTMaster) AcquireChangesChan() *NMTChangeChan {
 
 // ReleaseChangesChan release (close) a NMTChangeChan
 func (master *NMTMaster) ReleaseChangesChan(id string) error {
+	master.mutex.Lock()
+	defer master.mutex.Unlock()
+
 	var changesChan *NMTChangeChan
 	var changesChanIndex *int
 
@@ -0,0 +1,96 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNMTMaster_WaitForState(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, node.NMTMaster.WaitForState(ctx, 5), context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		network.Send(0x705, []byte{0x7F})
+		network.Send(0x705, []byte{0x05})
+	}()
+
+	start := time.Now()
+	assert.NoError(t, node.NMTMaster.WaitForState(context.Background(), 5))
+	assert.Less(t, time.Since(start), 80*time.Millisecond)
+	assert.Equal(t, 5, node.NMTMaster.GetState())
+	assert.Equal(t, "OPERATIONAL", node.NMTMaster.GetStateString())
+	assert.NotNil(t, node.NMTMaster.GetTimestamp())
+
+	// Already in state
+	assert.NoError(t, node.NMTMaster.WaitForState(context.Background(), 5))
+
+	// State received is reset by commands
+	assert.NoError(t, node.NMTMaster.SetState("OPERATIONAL"))
+	assert.Nil(t, node.NMTMaster.GetStateReceived())
+}
+
+func TestNMTMaster_WaitForBootup(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	// A previous boot-up is ignored
+	assert.NoError(t, network.Send(0x705, []byte{0x00}))
+	assert.NoError(t, node.NMTMaster.WaitForState(context.Background(), 0))
+
+	timeout := 50 * time.Millisecond
+	assert.ErrorIs(t, node.NMTMaster.WaitForBootup(&timeout), context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		network.Send(0x705, []byte{0x00})
+	}()
+
+	start := time.Now()
+	timeout = time.Second
+	assert.NoError(t, node.NMTMaster.WaitForBootup(&timeout))
+	assert.Less(t, time.Since(start), 80*time.Millisecond)
+
+	// Boot-up is PRE-OPERATIONAL
+	assert.Equal(t, 127, node.NMTMaster.GetState())
+	assert.Equal(t, 0, *node.NMTMaster.GetStateReceived())
+}
+
+// TestNMTMaster_ConcurrentState read the state while heartbeats are received, run it with -race
+func TestNMTMaster_ConcurrentState(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+	node.NMTMaster.SetHeartbeatTimeout(time.Second)
+
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			node.NMTMaster.GetState()
+			node.NMTMaster.GetStateReceived()
+			node.NMTMaster.GetTimestamp()
+		}
+	}()
+	defer close(done)
+
+	// Less heartbeats than the frames chan size, none is dropped
+	for i := 0; i < 100; i++ {
+		network.Send(0x705, []byte{[]uint8{0x7F, 0x05}[i%2]})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, node.NMTMaster.WaitForState(ctx, 5))
+}
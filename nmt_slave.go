@@ -0,0 +1,95 @@
+package canopen
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/jaster-prj/go-can"
+)
+
+// NMTSlave apply the start, stop and enter pre-operational commands addressed to a local node
+// to the state sent by its heartbeat producer. Reset commands are handled by the LSS slave,
+// which applies the pending node id.
+type NMTSlave struct {
+	sync.Mutex
+
+	Node *LocalNode
+
+	listening    bool
+	stopChan     chan bool
+	framesChanID string
+}
+
+// NewNMTSlave return a new NMTSlave for node
+func NewNMTSlave(node *LocalNode) *NMTSlave {
+	return &NMTSlave{Node: node}
+}
+
+// Listen for nmt commands on network
+func (slave *NMTSlave) Listen() error {
+	slave.Lock()
+	defer slave.Unlock()
+
+	if slave.Node.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	if slave.listening {
+		return nil
+	}
+
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == 0
+	}
+
+	framesChan := slave.Node.Network.AcquireFramesChan(&filterFunc)
+	slave.framesChanID = framesChan.ID
+	slave.stopChan = make(chan bool)
+	slave.listening = true
+
+	go func(stopChan chan bool) {
+		for {
+			select {
+			case <-stopChan:
+				return
+			case frm, ok := <-framesChan.C:
+				if !ok {
+					return
+				}
+				slave.handle(frm.GetData())
+			}
+		}
+	}(slave.stopChan)
+
+	return nil
+}
+
+// Unlisten stop listening nmt commands
+func (slave *NMTSlave) Unlisten() {
+	slave.Lock()
+	defer slave.Unlock()
+
+	if !slave.listening {
+		return
+	}
+
+	close(slave.stopChan)
+	slave.Node.Network.ReleaseFramesChan(slave.framesChanID)
+	slave.listening = false
+}
+
+// handle set the heartbeat state on a state change command for the node, or for all nodes
+func (slave *NMTSlave) handle(data []byte) {
+	if len(data) != 2 || !slave.Node.hasValidID() {
+		return
+	}
+
+	if data[1] != 0 && int(data[1]) != slave.Node.GetId() {
+		return
+	}
+
+	switch code := int(data[0]); code {
+	case NMTCommands["OPERATIONAL"], NMTCommands["STOPPED"], NMTCommands["PRE-OPERATIONAL"]:
+		slave.Node.HeartbeatProducer.SetState(NMTCommandToState[code])
+	}
+}
@@ -1,6 +1,9 @@
 package canopen
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 // Node is a canopen node
 type Node struct {
@@ -10,9 +13,15 @@ type Node struct {
 	Network   *Network
 	ObjectDic *DicObjectDic
 
+	// SDOClient use the default sdo channel, typed values are read and written
+	// with its methods, e.g. node.SDOClient.ReadU32
 	SDOClient *SDOClient
-	PDONode   *PDONode
-	NMTMaster *NMTMaster
+	// SDOClients by channel, including the default one at channel 0
+	SDOClients map[uint8]*SDOClient
+
+	PDONode      *PDONode
+	NMTMaster    *NMTMaster
+	EmcyConsumer *EmcyConsumer
 }
 
 func NewNode(id int, network *Network, objectDic *DicObjectDic) *Node {
@@ -74,11 +83,91 @@ func (node *Node) SetObjectDic(objectDic *DicObjectDic) {
 // Init create sdo clients, pdo nodes, nmt master
 func (node *Node) Init() {
 	node.SDOClient = NewSDOClient(node)
+	node.SDOClients = map[uint8]*SDOClient{0: node.SDOClient}
 	node.PDONode = NewPDONode(node)
 	node.NMTMaster = NewNMTMaster(node.ID, node.Network)
+	node.EmcyConsumer = NewEmcyConsumer(node.ID, node.Network)
 
 	// @TODO: list for NMTMaster
-	// @TODO: implement EMCY
+}
+
+// AddSDOClient add an sdo client on channel, using the given cob-ids.
+// Channel 0 replace the default sdo client.
+func (node *Node) AddSDOClient(channel uint8, rxCobID uint32, txCobID uint32) *SDOClient {
+	if node.SDOClients == nil {
+		node.SDOClients = map[uint8]*SDOClient{}
+	}
+
+	sdoClient := NewSDOClientWithCobIDs(node, rxCobID, txCobID)
+	node.SDOClients[channel] = sdoClient
+
+	if channel == 0 {
+		node.SDOClient = sdoClient
+	}
+
+	return sdoClient
+}
+
+// GetSDOClient returns the sdo client of channel, nil if not found
+func (node *Node) GetSDOClient(channel uint8) *SDOClient {
+	return node.SDOClients[channel]
+}
+
+// DiscoverSDOChannels read the sdo server parameters 0x1201-0x127F of the remote node
+// with the default sdo client, and add an sdo client for each valid channel found
+func (node *Node) DiscoverSDOChannels(ctx context.Context) ([]SDOChannel, error) {
+	if node.SDOClient == nil {
+		return nil, errors.New("SDOClient required")
+	}
+
+	channels := []SDOChannel{}
+
+	for channel := 1; channel < SDOChannelsCount; channel++ {
+		index := SDOServerParameterIndex + uint16(channel)
+
+		rxCobID, err := node.SDOClient.ReadContext(ctx, index, 1)
+		if isSDOChannelAbsent(err) {
+			continue
+		} else if err != nil {
+			return channels, err
+		}
+
+		txCobID, err := node.SDOClient.ReadContext(ctx, index, 2)
+		if isSDOChannelAbsent(err) {
+			continue
+		} else if err != nil {
+			return channels, err
+		}
+
+		sdoChannel, err := newSDOChannel(uint8(channel), rxCobID, txCobID, nil)
+		if err != nil {
+			return channels, err
+		}
+
+		if !sdoChannel.Valid() {
+			continue
+		}
+
+		node.AddSDOClient(sdoChannel.Channel, sdoChannel.RXCobID, sdoChannel.TXCobID)
+		channels = append(channels, *sdoChannel)
+	}
+
+	return channels, nil
+}
+
+// isSDOChannelAbsent returns true if err is an abort of a server without the sdo parameter read
+func isSDOChannelAbsent(err error) bool {
+	var abortErr *SDOAbortError
+	if !errors.As(err, &abortErr) {
+		return false
+	}
+
+	switch abortErr.Code {
+	case SDOAbortObjectDoesNotExist, SDOAbortSubIndexDoesNotExist, SDOAbortNoData:
+		return true
+	}
+
+	return false
 }
 
 // Stop node
@@ -86,6 +175,9 @@ func (node *Node) Stop() {
 	// Stop nmt master
 	node.NMTMaster.UnlistenForHeartbeat()
 
+	// Stop emcy consumer
+	node.EmcyConsumer.Unlisten()
+
 	// Stop pdo listeners
 	for _, mm := range node.PDONode.RX.Maps {
 		mm.Unlisten()
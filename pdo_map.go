@@ -1,6 +1,7 @@
 package canopen
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"sync"
@@ -221,8 +222,13 @@ func (m *PDOMap) ReleaseChangesChan(id string) error {
 
 // Read map values
 func (m *PDOMap) Read() error {
+	return m.ReadContext(context.Background())
+}
+
+// ReadContext read map values, until ctx is done
+func (m *PDOMap) ReadContext(ctx context.Context) error {
 	// Get COB ID
-	if err := m.ComRecord.FindIndex(1).Read(); err != nil {
+	if err := m.ComRecord.FindIndex(1).ReadContext(ctx); err != nil {
 		return err
 	}
 
@@ -236,7 +242,7 @@ func (m *PDOMap) Read() error {
 	m.RTRAllowed = (cobID & MapRTRNotAllowed) == 0
 
 	// Get Trans type
-	if err := m.ComRecord.FindIndex(2).Read(); err != nil {
+	if err := m.ComRecord.FindIndex(2).ReadContext(ctx); err != nil {
 		return err
 	}
 
@@ -248,7 +254,7 @@ func (m *PDOMap) Read() error {
 		comr := m.ComRecord.FindIndex(5)
 
 		if comr != nil {
-			if err := comr.Read(); err != nil {
+			if err := comr.ReadContext(ctx); err != nil {
 				return err
 			}
 
@@ -261,7 +267,7 @@ func (m *PDOMap) Read() error {
 	offset := 0
 
 	// Nof entries
-	if err := m.MapArray.FindIndex(0).Read(); err != nil {
+	if err := m.MapArray.FindIndex(0).ReadContext(ctx); err != nil {
 		return err
 	}
 
@@ -269,7 +275,7 @@ func (m *PDOMap) Read() error {
 
 	for i := 1; i < (nofEntries + 1); i++ {
 		ii := uint16(i)
-		if err := m.MapArray.FindIndex(ii).Read(); err != nil {
+		if err := m.MapArray.FindIndex(ii).ReadContext(ctx); err != nil {
 			return err
 		}
 
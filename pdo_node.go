@@ -1,5 +1,7 @@
 package canopen
 
+import "context"
+
 type PDONode struct {
 	Node *Node
 	RX   *PDOMaps
@@ -24,9 +26,14 @@ func (node *PDONode) FindName(name string) *PDOMap {
 }
 
 func (node *PDONode) Read() error {
+	return node.ReadContext(context.Background())
+}
+
+// ReadContext read all maps, until ctx is done
+func (node *PDONode) ReadContext(ctx context.Context) error {
 	for _, maps := range []*PDOMaps{node.RX, node.TX} {
 		for _, v := range maps.Maps {
-			if err := v.Read(); err != nil {
+			if err := v.ReadContext(ctx); err != nil {
 				return err
 			}
 		}
@@ -1,6 +1,7 @@
 package canopen
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -18,29 +19,71 @@ const (
 	SDORequestSegmentDownload  uint8 = 0 << 5
 	SDOResponseSegmentDownload uint8 = 1 << 5
 
+	SDORequestBlockUpload    uint8 = 5 << 5
+	SDOResponseBlockUpload   uint8 = 6 << 5
+	SDORequestBlockDownload  uint8 = 6 << 5
+	SDOResponseBlockDownload uint8 = 5 << 5
+
+	SDOAbort uint8 = 4 << 5
+
 	SDOExpedited     uint8 = 0x2
 	SDOSizeSpecified uint8 = 0x1
 	SDOToggleBit     uint8 = 0x10
 	SDONoMoreData    uint8 = 0x1
+
+	SDOBlockCRC           uint8 = 0x4
+	SDOBlockSizeSpecified uint8 = 0x2
+	SDOBlockInitiate      uint8 = 0x0
+	SDOBlockEnd           uint8 = 0x1
+	SDOBlockAck           uint8 = 0x2
+	SDOBlockStartUpload   uint8 = 0x3
+	SDOBlockNoMoreData    uint8 = 0x80
+	SDOBlockMaxSize       uint8 = 127
 )
 
-// SDOClient represent an SDO client
+const sdoDefaultTimeout = time.Duration(500) * time.Millisecond
+
+// ErrSDOTimeout is returned when no response was received from the remote node
+var ErrSDOTimeout = errors.New("sdo timeout exceeded")
+
+// ErrSDOBlockNotSupported is returned when the server reject a block transfer
+var ErrSDOBlockNotSupported = errors.New("sdo block transfer not supported by server")
+
+// SDOClient represent an SDO client.
+// Read, Write, OpenReader and OpenWriter are safe for concurrent use:
+// transactions are queued and run one after the other, in call order.
 type SDOClient struct {
-	Node      INode
-	RXCobID   uint32
-	TXCobID   uint32
-	SendQueue []string
+	Node    INode
+	RXCobID uint32
+	TXCobID uint32
+
+	// Options of timeouts and retries, overridable on each transfer
+	Options SDOClientOptions
+
+	queue sdoTransactionQueue
 }
 
+// NewSDOClient return a new SDOClient using the default sdo channel of node
 func NewSDOClient(node INode) *SDOClient {
+	return NewSDOClientWithCobIDs(node, uint32(0x600+node.GetId()), uint32(0x580+node.GetId()))
+}
+
+// NewSDOClientWithCobIDs return a new SDOClient sending requests with rxCobID,
+// and receiving responses with txCobID
+func NewSDOClientWithCobIDs(node INode, rxCobID uint32, txCobID uint32) *SDOClient {
 	return &SDOClient{
-		Node:      node,
-		RXCobID:   uint32(0x600 + node.GetId()),
-		TXCobID:   uint32(0x580 + node.GetId()),
-		SendQueue: []string{},
+		Node:    node,
+		RXCobID: rxCobID,
+		TXCobID: txCobID,
+		Options: DefaultSDOClientOptions(),
 	}
 }
 
+// GetOptions returns the options of timeouts and retries of the client
+func (sdoClient *SDOClient) GetOptions() SDOClientOptions {
+	return sdoClient.Options
+}
+
 // SendRequest to network bus
 func (sdoClient *SDOClient) SendRequest(req []byte) error {
 	return sdoClient.Node.Send(sdoClient.RXCobID, req)
@@ -62,6 +105,38 @@ func (sdoClient *SDOClient) Send(
 	expectFunc networkFramesChanFilterFunc,
 	timeout *time.Duration,
 	retryCount *int,
+) (*can.Frame, error) {
+	return sdoClient.SendContext(context.Background(), req, expectFunc, timeout, retryCount)
+}
+
+// SendContext send message and optionaly wait for response, until ctx is done.
+// timeout and retryCount, the total number of tries, override the options of the client if not nil.
+func (sdoClient *SDOClient) SendContext(
+	ctx context.Context,
+	req []byte,
+	expectFunc networkFramesChanFilterFunc,
+	timeout *time.Duration,
+	retryCount *int,
+) (*can.Frame, error) {
+	options := sdoClient.Options
+
+	if timeout != nil {
+		options.Timeout = *timeout
+	}
+
+	if retryCount != nil {
+		options.Retries = *retryCount - 1
+	}
+
+	return sdoClient.SendOptions(ctx, req, expectFunc, options)
+}
+
+// SendOptions send message and optionaly wait for response, with given timeouts and retries
+func (sdoClient *SDOClient) SendOptions(
+	ctx context.Context,
+	req []byte,
+	expectFunc networkFramesChanFilterFunc,
+	options SDOClientOptions,
 ) (*can.Frame, error) {
 	// If no response wanted, just send and return
 	if expectFunc == nil {
@@ -72,75 +147,129 @@ func (sdoClient *SDOClient) Send(
 		return nil, nil
 	}
 
-	// Set default timeout
-	if timeout == nil {
-		dtm := time.Duration(500) * time.Millisecond
-		timeout = &dtm
-	}
-
-	if retryCount == nil {
-		rtc := 4
-		retryCount = &rtc
-	}
+	options = options.forRequest(true)
 
-	var expectSdoFunc networkFramesChanFilterFunc
-	if expectFunc != nil {
-		expectSdoFilterFunc := func(frm *can.Frame) bool {
-			arbitrationId := frm.ArbitrationID
-			if arbitrationId != sdoClient.TXCobID {
-				return false
-			}
-			return (*expectFunc)(frm)
+	expectSdoFilterFunc := func(frm *can.Frame) bool {
+		if frm.ArbitrationID != sdoClient.TXCobID {
+			return false
 		}
-		expectSdoFunc = &expectSdoFilterFunc
+		return (*expectFunc)(frm)
 	}
-	framesChan := sdoClient.Node.AcquireFramesChanFromNetwork(expectSdoFunc)
+	framesChan := sdoClient.Node.AcquireFramesChanFromNetwork(&expectSdoFilterFunc)
 	defer sdoClient.Node.ReleaseFramesChanFromNetwork(framesChan.ID)
 
 	// Retry loop
-	remainingCount := *retryCount
-	for {
-		if remainingCount == 0 {
-			break
-		}
-
+	timeout := options.Timeout
+	for try := 0; try <= options.Retries; try++ {
 		if err := sdoClient.SendRequest(req); err != nil {
 			return nil, err
 		}
 
-		timer := time.NewTimer(*timeout)
+		timer := time.NewTimer(timeout)
 
-		loop := true
-		for {
-			if !loop {
-				break
-			}
-			select {
-			case <-timer.C:
-				// Double timeout for each retry
-				newTimeout := *timeout * 2
-				timeout = &newTimeout
-				loop = false
-			case fr := <-framesChan.C:
-				return fr, nil
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+			timeout = options.Backoff(timeout)
+		case fr := <-framesChan.C:
+			timer.Stop()
+			if abortErr := newSDOAbortErrorFromFrame(fr); abortErr != nil {
+				return nil, abortErr
 			}
+			return fr, nil
 		}
-
-		timer.Stop()
-		remainingCount--
 	}
 
-	return nil, errors.New("timeout execeded")
+	return nil, ErrSDOTimeout
+}
+
+// Abort the transfer of index / subIndex with given abort code
+func (sdoClient *SDOClient) Abort(index uint16, subIndex uint8, code uint32) error {
+	return sdoClient.SendRequest(buildSDOAbortBuf(index, subIndex, code))
 }
 
 // Read sdo
-func (sdoClient *SDOClient) Read(index uint16, subIndex uint8) ([]byte, error) {
+func (sdoClient *SDOClient) Read(index uint16, subIndex uint8, opts ...SDOOption) ([]byte, error) {
+	return sdoClient.ReadContext(context.Background(), index, subIndex, opts...)
+}
+
+// ReadContext read sdo until ctx is done. If ctx is done during the transfer, the transfer is aborted.
+func (sdoClient *SDOClient) ReadContext(ctx context.Context, index uint16, subIndex uint8, opts ...SDOOption) ([]byte, error) {
+	if sdoClient == nil {
+		return nil, errors.New("SDOClient required")
+	}
+
+	if err := sdoClient.queue.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer sdoClient.queue.release()
+
+	options := NewSDOTransferOptions(opts...)
+
+	if options.Block {
+		reader := NewSDOReader(sdoClient, index, subIndex)
+		reader.SetContext(ctx)
+		reader.SetOptions(options.ClientOptions(sdoClient.Options))
+		data, err := reader.ReadAllBlock(options.BlockSize, options.CRC)
+		if !errors.Is(err, ErrSDOBlockNotSupported) {
+			return data, sdoClient.abortOnContextError(index, subIndex, err)
+		}
+
+		// Fallback to segmented transfer
+	}
+
 	reader := NewSDOReader(sdoClient, index, subIndex)
-	return reader.ReadAll()
+	reader.SetContext(ctx)
+	reader.SetOptions(options.ClientOptions(sdoClient.Options))
+	data, err := reader.ReadAll()
+	return data, sdoClient.abortOnContextError(index, subIndex, err)
 }
 
 // Write sdo
-func (sdoClient *SDOClient) Write(index uint16, subIndex uint8, forceSegment bool, data []byte) error {
+func (sdoClient *SDOClient) Write(index uint16, subIndex uint8, forceSegment bool, data []byte, opts ...SDOOption) error {
+	return sdoClient.WriteContext(context.Background(), index, subIndex, forceSegment, data, opts...)
+}
+
+// WriteContext write sdo until ctx is done. If ctx is done during the transfer, the transfer is aborted.
+func (sdoClient *SDOClient) WriteContext(ctx context.Context, index uint16, subIndex uint8, forceSegment bool, data []byte, opts ...SDOOption) error {
+	if sdoClient == nil {
+		return errors.New("SDOClient required")
+	}
+
+	if err := sdoClient.queue.acquire(ctx); err != nil {
+		return err
+	}
+	defer sdoClient.queue.release()
+
+	options := NewSDOTransferOptions(opts...)
+
+	if options.Block {
+		writer := NewSDOWriter(sdoClient, index, subIndex, forceSegment)
+		writer.SetContext(ctx)
+		writer.SetOptions(options.ClientOptions(sdoClient.Options))
+		err := writer.WriteBlock(data, options.CRC)
+		if !errors.Is(err, ErrSDOBlockNotSupported) {
+			return sdoClient.abortOnContextError(index, subIndex, err)
+		}
+
+		// Fallback to segmented transfer
+	}
+
 	writer := NewSDOWriter(sdoClient, index, subIndex, forceSegment)
-	return writer.Write(data)
+	writer.SetContext(ctx)
+	writer.SetOptions(options.ClientOptions(sdoClient.Options))
+	return sdoClient.abortOnContextError(index, subIndex, writer.Write(data))
+}
+
+// abortOnContextError send an abort transfer request if err is a context error, and returns err
+func (sdoClient *SDOClient) abortOnContextError(index uint16, subIndex uint8, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		sdoClient.Abort(index, subIndex, SDOAbortTimeout)
+	} else if errors.Is(err, context.Canceled) {
+		sdoClient.Abort(index, subIndex, SDOAbortGeneral)
+	}
+
+	return err
 }
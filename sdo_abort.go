@@ -0,0 +1,149 @@
+package canopen
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/jaster-prj/go-can"
+)
+
+// SDO abort codes as defined by CiA 301
+const (
+	SDOAbortToggleBit             uint32 = 0x05030000
+	SDOAbortTimeout               uint32 = 0x05040000
+	SDOAbortCommandSpecifier      uint32 = 0x05040001
+	SDOAbortBlockSize             uint32 = 0x05040002
+	SDOAbortSequenceNumber        uint32 = 0x05040003
+	SDOAbortCRC                   uint32 = 0x05040004
+	SDOAbortOutOfMemory           uint32 = 0x05040005
+	SDOAbortUnsupportedAccess     uint32 = 0x06010000
+	SDOAbortWriteOnly             uint32 = 0x06010001
+	SDOAbortReadOnly              uint32 = 0x06010002
+	SDOAbortObjectDoesNotExist    uint32 = 0x06020000
+	SDOAbortNotMappable           uint32 = 0x06040041
+	SDOAbortPDOLength             uint32 = 0x06040042
+	SDOAbortParameterIncompatible uint32 = 0x06040043
+	SDOAbortInternalIncompatible  uint32 = 0x06040047
+	SDOAbortHardware              uint32 = 0x06060000
+	SDOAbortTypeLength            uint32 = 0x06070010
+	SDOAbortTypeLengthTooHigh     uint32 = 0x06070012
+	SDOAbortTypeLengthTooLow      uint32 = 0x06070013
+	SDOAbortSubIndexDoesNotExist  uint32 = 0x06090011
+	SDOAbortInvalidValue          uint32 = 0x06090030
+	SDOAbortValueTooHigh          uint32 = 0x06090031
+	SDOAbortValueTooLow           uint32 = 0x06090032
+	SDOAbortMaxLessThanMin        uint32 = 0x06090036
+	SDOAbortNoResource            uint32 = 0x060A0023
+	SDOAbortGeneral               uint32 = 0x08000000
+	SDOAbortDataTransfer          uint32 = 0x08000020
+	SDOAbortDataLocalControl      uint32 = 0x08000021
+	SDOAbortDataDeviceState       uint32 = 0x08000022
+	SDOAbortNoObjectDic           uint32 = 0x08000023
+	SDOAbortNoData                uint32 = 0x08000024
+)
+
+// SDOAbortCodes map CiA 301 abort codes to a human readable description
+var SDOAbortCodes = map[uint32]string{
+	SDOAbortToggleBit:             "Toggle bit not alternated",
+	SDOAbortTimeout:               "SDO protocol timed out",
+	SDOAbortCommandSpecifier:      "Client/server command specifier not valid or unknown",
+	SDOAbortBlockSize:             "Invalid block size (block mode only)",
+	SDOAbortSequenceNumber:        "Invalid sequence number (block mode only)",
+	SDOAbortCRC:                   "CRC error (block mode only)",
+	SDOAbortOutOfMemory:           "Out of memory",
+	SDOAbortUnsupportedAccess:     "Unsupported access to an object",
+	SDOAbortWriteOnly:             "Attempt to read a write only object",
+	SDOAbortReadOnly:              "Attempt to write a read only object",
+	SDOAbortObjectDoesNotExist:    "Object does not exist in the object dictionary",
+	SDOAbortNotMappable:           "Object cannot be mapped to the PDO",
+	SDOAbortPDOLength:             "The number and length of the objects to be mapped would exceed PDO length",
+	SDOAbortParameterIncompatible: "General parameter incompatibility reason",
+	SDOAbortInternalIncompatible:  "General internal incompatibility in the device",
+	SDOAbortHardware:              "Access failed due to a hardware error",
+	SDOAbortTypeLength:            "Data type does not match, length of service parameter does not match",
+	SDOAbortTypeLengthTooHigh:     "Data type does not match, length of service parameter too high",
+	SDOAbortTypeLengthTooLow:      "Data type does not match, length of service parameter too low",
+	SDOAbortSubIndexDoesNotExist:  "Sub-index does not exist",
+	SDOAbortInvalidValue:          "Invalid value for parameter (download only)",
+	SDOAbortValueTooHigh:          "Value of parameter written too high (download only)",
+	SDOAbortValueTooLow:           "Value of parameter written too low (download only)",
+	SDOAbortMaxLessThanMin:        "Maximum value is less than minimum value",
+	SDOAbortNoResource:            "Resource not available: SDO connection",
+	SDOAbortGeneral:               "General error",
+	SDOAbortDataTransfer:          "Data cannot be transferred or stored to the application",
+	SDOAbortDataLocalControl:      "Data cannot be transferred or stored to the application because of local control",
+	SDOAbortDataDeviceState:       "Data cannot be transferred or stored to the application because of the present device state",
+	SDOAbortNoObjectDic:           "Object dictionary dynamic generation fails or no object dictionary is present",
+	SDOAbortNoData:                "No data available",
+}
+
+// SDOAbortError is returned when a SDO transfer was aborted by the remote node
+type SDOAbortError struct {
+	Index    uint16
+	SubIndex uint8
+	Code     uint32
+}
+
+// NewSDOAbortError return a new SDOAbortError
+func NewSDOAbortError(index uint16, subIndex uint8, code uint32) *SDOAbortError {
+	return &SDOAbortError{
+		Index:    index,
+		SubIndex: subIndex,
+		Code:     code,
+	}
+}
+
+// Description of the abort code, from SDOAbortCodes
+func (abortErr *SDOAbortError) Description() string {
+	if desc, ok := SDOAbortCodes[abortErr.Code]; ok {
+		return desc
+	}
+
+	return "Unknown abort code"
+}
+
+// Error implement the error interface
+func (abortErr *SDOAbortError) Error() string {
+	return fmt.Sprintf(
+		"sdo transfer aborted on 0x%04X:%02X with code 0x%08X: %s",
+		abortErr.Index,
+		abortErr.SubIndex,
+		abortErr.Code,
+		abortErr.Description(),
+	)
+}
+
+// newSDOAbortErrorFromFrame returns nil if frm is not an abort transfer frame
+func newSDOAbortErrorFromFrame(frm *can.Frame) *SDOAbortError {
+	if frm == nil || frm.Data[0] != SDOAbort {
+		return nil
+	}
+
+	return NewSDOAbortError(
+		binary.LittleEndian.Uint16(frm.Data[1:]),
+		frm.Data[3],
+		binary.LittleEndian.Uint32(frm.Data[4:]),
+	)
+}
+
+// isSDOAbortFrame returns true if frm abort the transfer of index / subIndex
+func isSDOAbortFrame(frm *can.Frame, index uint16, subIndex uint8) bool {
+	abortErr := newSDOAbortErrorFromFrame(frm)
+	if abortErr == nil {
+		return false
+	}
+
+	return abortErr.Index == index && abortErr.SubIndex == subIndex
+}
+
+// buildSDOAbortBuf build an abort transfer request
+func buildSDOAbortBuf(index uint16, subIndex uint8, code uint32) []byte {
+	buf := make([]byte, 8)
+
+	buf[0] = SDOAbort
+	binary.LittleEndian.PutUint16(buf[1:], index)
+	buf[3] = subIndex
+	binary.LittleEndian.PutUint32(buf[4:], code)
+
+	return buf
+}
@@ -0,0 +1,96 @@
+package canopen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ObjectAddress is the index and sub-index of an object
+type ObjectAddress struct {
+	Index    uint16
+	SubIndex uint8
+}
+
+func (address ObjectAddress) String() string {
+	return fmt.Sprintf("0x%04X/%d", address.Index, address.SubIndex)
+}
+
+// SDOResult is the result of the upload of an object
+type SDOResult struct {
+	Data []byte
+	Err  error
+}
+
+// SDOProgressFunc is called after the transfer of address, with the number of transfers done out of total
+type SDOProgressFunc func(address ObjectAddress, err error, done int, total int)
+
+// ReadMany read each address one after the other. A failed transfer does not stop the others,
+// the result of each address is returned.
+func (sdoClient *SDOClient) ReadMany(addresses []ObjectAddress, opts ...SDOOption) map[ObjectAddress]SDOResult {
+	return sdoClient.ReadManyContext(context.Background(), addresses, opts...)
+}
+
+// ReadManyContext read each address one after the other, until ctx is done.
+// Addresses not read when ctx is done get ctx error as result.
+func (sdoClient *SDOClient) ReadManyContext(ctx context.Context, addresses []ObjectAddress, opts ...SDOOption) map[ObjectAddress]SDOResult {
+	options := NewSDOTransferOptions(opts...)
+	results := make(map[ObjectAddress]SDOResult, len(addresses))
+
+	for i, address := range addresses {
+		var result SDOResult
+
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+		} else {
+			result.Data, result.Err = sdoClient.ReadContext(ctx, address.Index, address.SubIndex, opts...)
+		}
+
+		results[address] = result
+
+		if options.Progress != nil {
+			options.Progress(address, result.Err, i+1, len(addresses))
+		}
+	}
+
+	return results
+}
+
+// WriteMany write each value one after the other, ordered by address. A failed transfer
+// does not stop the others, the error of each address is returned, nil on success.
+func (sdoClient *SDOClient) WriteMany(values map[ObjectAddress][]byte, opts ...SDOOption) map[ObjectAddress]error {
+	return sdoClient.WriteManyContext(context.Background(), values, opts...)
+}
+
+// WriteManyContext write each value one after the other, ordered by address, until ctx is done.
+// Addresses not written when ctx is done get ctx error.
+func (sdoClient *SDOClient) WriteManyContext(ctx context.Context, values map[ObjectAddress][]byte, opts ...SDOOption) map[ObjectAddress]error {
+	options := NewSDOTransferOptions(opts...)
+	results := make(map[ObjectAddress]error, len(values))
+
+	addresses := make([]ObjectAddress, 0, len(values))
+	for address := range values {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		if addresses[i].Index != addresses[j].Index {
+			return addresses[i].Index < addresses[j].Index
+		}
+		return addresses[i].SubIndex < addresses[j].SubIndex
+	})
+
+	for i, address := range addresses {
+		err := ctx.Err()
+		if err == nil {
+			err = sdoClient.WriteContext(ctx, address.Index, address.SubIndex, false, values[address], opts...)
+		}
+
+		results[address] = err
+
+		if options.Progress != nil {
+			options.Progress(address, err, i+1, len(addresses))
+		}
+	}
+
+	return results
+}
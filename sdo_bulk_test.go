@@ -0,0 +1,71 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSDOClient_ReadMany(t *testing.T) {
+	_, node := getLocalAndRemoteNodes(t)
+
+	addresses := []ObjectAddress{
+		{Index: 0x1000},
+		{Index: 0x3000},
+		{Index: 0x2000},
+		{Index: 0x2003, SubIndex: 1},
+	}
+
+	progress := []ObjectAddress{}
+	results := node.SDOClient.ReadMany(addresses, WithProgress(func(address ObjectAddress, err error, done int, total int) {
+		assert.Equal(t, len(progress)+1, done)
+		assert.Equal(t, 4, total)
+		progress = append(progress, address)
+	}))
+
+	assert.Equal(t, addresses, progress)
+	assert.Len(t, results, 4)
+	assert.Equal(t, SDOResult{Data: []byte{0x92, 0x01, 0x02, 0x00}}, results[ObjectAddress{Index: 0x1000}])
+	assert.Equal(t, SDOResult{Data: []byte{0x15, 0x00}}, results[ObjectAddress{Index: 0x2000}])
+	assert.Equal(t, SDOResult{Data: []byte{0xFB, 0xFF, 0xFF, 0xFF}}, results[ObjectAddress{Index: 0x2003, SubIndex: 1}])
+	assertSDOAbortCode(t, results[ObjectAddress{Index: 0x3000}].Err, SDOAbortObjectDoesNotExist)
+
+	// Context done before the transfers
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results = node.SDOClient.ReadManyContext(ctx, addresses)
+	for _, address := range addresses {
+		assert.ErrorIs(t, results[address].Err, context.Canceled)
+	}
+}
+
+func TestSDOClient_WriteMany(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+
+	values := map[ObjectAddress][]byte{
+		{Index: 0x2003, SubIndex: 1}: {0x01, 0x00, 0x00, 0x00},
+		{Index: 0x1000}:              {0x00, 0x00, 0x00, 0x00},
+		{Index: 0x2000}:              {0x34, 0x12},
+		{Index: 0x2001}:              []byte("firmware"),
+	}
+
+	progress := []ObjectAddress{}
+	results := node.SDOClient.WriteMany(values, WithProgress(func(address ObjectAddress, err error, done int, total int) {
+		progress = append(progress, address)
+	}))
+
+	// Written in address order
+	assert.Equal(t, []ObjectAddress{{Index: 0x1000}, {Index: 0x2000}, {Index: 0x2001}, {Index: 0x2003, SubIndex: 1}}, progress)
+
+	assert.Len(t, results, 4)
+	assertSDOAbortCode(t, results[ObjectAddress{Index: 0x1000}], SDOAbortReadOnly)
+	assert.NoError(t, results[ObjectAddress{Index: 0x2000}])
+	assert.NoError(t, results[ObjectAddress{Index: 0x2001}])
+	assert.NoError(t, results[ObjectAddress{Index: 0x2003, SubIndex: 1}])
+
+	assert.Equal(t, []byte{0x34, 0x12}, localNode.ObjectDic.FindIndex(0x2000).GetData())
+	assert.Equal(t, []byte("firmware"), localNode.ObjectDic.FindIndex(0x2001).GetData())
+	assert.Equal(t, []byte{0x01, 0x00, 0x00, 0x00}, localNode.ObjectDic.FindIndex(0x2003).FindIndex(1).GetData())
+}
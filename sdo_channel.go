@@ -0,0 +1,96 @@
+package canopen
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// SDOServerParameterIndex is the index of the default sdo server parameter,
+	// additional servers use the following indexes up to 0x127F
+	SDOServerParameterIndex uint16 = 0x1200
+	// SDOClientParameterIndex is the index of the first sdo client parameter,
+	// additional clients use the following indexes up to 0x12FF
+	SDOClientParameterIndex uint16 = 0x1280
+	// SDOChannelsCount is the maximum number of sdo servers, or sdo clients, of a node
+	SDOChannelsCount = 128
+
+	// SDOCobIDInvalid is set in a cob-id when the channel is not used
+	SDOCobIDInvalid uint32 = 0x80000000
+	// SDOCobIDDynamic is set in a cob-id when the channel is dynamically allocated
+	SDOCobIDDynamic uint32 = 0x40000000
+)
+
+// SDOChannel is the parameter of an sdo channel, as found in 0x1200-0x12FF
+type SDOChannel struct {
+	// Channel is the position of the parameter object, from 0x1200 for servers, or from 0x1280 for clients
+	Channel uint8
+	// RXCobID is the cob-id of requests, from client to server
+	RXCobID uint32
+	// TXCobID is the cob-id of responses, from server to client
+	TXCobID uint32
+	// NodeID is the id of the node on the other side of the channel, 0 if not given
+	NodeID uint8
+}
+
+// newSDOChannel build an sdo channel from sub-indexes 1 to 3 of a parameter object.
+// For servers and clients parameters, sub-index 1 is the client to server cob-id.
+func newSDOChannel(channel uint8, rxCobID []byte, txCobID []byte, nodeID []byte) (*SDOChannel, error) {
+	if len(rxCobID) < 4 || len(txCobID) < 4 {
+		return nil, errors.New("invalid sdo channel cob-id")
+	}
+
+	sdoChannel := &SDOChannel{
+		Channel: channel,
+		RXCobID: binary.LittleEndian.Uint32(rxCobID),
+		TXCobID: binary.LittleEndian.Uint32(txCobID),
+	}
+
+	if len(nodeID) > 0 {
+		sdoChannel.NodeID = nodeID[0]
+	}
+
+	return sdoChannel, nil
+}
+
+// Valid returns false if the channel is disabled by the invalid bit of one of its cob-id
+func (sdoChannel SDOChannel) Valid() bool {
+	return sdoChannel.RXCobID&SDOCobIDInvalid == 0 && sdoChannel.TXCobID&SDOCobIDInvalid == 0
+}
+
+// sdoChannelsFromObjectDic read valid sdo channels from the parameter objects
+// at index..index+SDOChannelsCount of objectDic
+func sdoChannelsFromObjectDic(objectDic *DicObjectDic, index uint16) []SDOChannel {
+	channels := []SDOChannel{}
+
+	if objectDic == nil {
+		return channels
+	}
+
+	for channel := 0; channel < SDOChannelsCount; channel++ {
+		object := objectDic.FindIndex(index + uint16(channel))
+		if object == nil || object.IsDicVariable() {
+			continue
+		}
+
+		rxCobID := object.FindIndex(1)
+		txCobID := object.FindIndex(2)
+		if rxCobID == nil || txCobID == nil {
+			continue
+		}
+
+		var nodeID []byte
+		if sub := object.FindIndex(3); sub != nil {
+			nodeID = sub.GetData()
+		}
+
+		sdoChannel, err := newSDOChannel(uint8(channel), rxCobID.GetData(), txCobID.GetData(), nodeID)
+		if err != nil || !sdoChannel.Valid() {
+			continue
+		}
+
+		channels = append(channels, *sdoChannel)
+	}
+
+	return channels
+}
@@ -0,0 +1,100 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getSDOParameterRecord(index uint16, rxCobID string, txCobID string, nodeID string) *DicRecord {
+	record := &DicRecord{Index: index, Name: "SDO parameter"}
+	record.AddMember(&DicVariable{Index: index, SubIndex: 0, Name: "Highest sub-index supported", DataType: Unsigned8, AccessType: "const", Default: []byte("3")})
+	record.AddMember(&DicVariable{Index: index, SubIndex: 1, Name: "COB-ID client to server", DataType: Unsigned32, AccessType: "rw", Default: []byte(rxCobID)})
+	record.AddMember(&DicVariable{Index: index, SubIndex: 2, Name: "COB-ID server to client", DataType: Unsigned32, AccessType: "rw", Default: []byte(txCobID)})
+	record.AddMember(&DicVariable{Index: index, SubIndex: 3, Name: "Node-ID", DataType: Unsigned8, AccessType: "rw", Default: []byte(nodeID)})
+	return record
+}
+
+func TestNode_DiscoverSDOChannels(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	dic := getLocalObjectDic()
+	dic.AddObject(getSDOParameterRecord(0x1201, "$NODEID+0x640", "$NODEID+0x5C0", "0"))
+	dic.AddObject(getSDOParameterRecord(0x1202, "0x80000000", "0x80000000", "0"))
+	// Unused entries answered with sub-index or data aborts are skipped
+	dic.AddObject(&DicRecord{Index: 0x1203, Name: "SDO parameter", SubIndexes: map[uint8]DicObject{}})
+	dic.AddObject(getSDOParameterRecord(0x1204, "0x80000000", "0x80000000", "0"))
+	dic.AddObject(getSDOParameterRecord(0x1205, "$NODEID+0x650", "$NODEID+0x5D0", "0"))
+
+	localNode, err := network.AddLocalNode(NewLocalNode(5, nil, nil), dic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, localNode.SDOServers, 3)
+
+	localNode.SDOServer.OnRead(0x1204, 1, func(index uint16, subIndex uint8) ([]byte, error) {
+		return nil, NewSDOAbortError(index, subIndex, SDOAbortNoData)
+	})
+
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	channels, err := node.DiscoverSDOChannels(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []SDOChannel{
+		{Channel: 1, RXCobID: 0x645, TXCobID: 0x5C5},
+		{Channel: 5, RXCobID: 0x655, TXCobID: 0x5D5},
+	}, channels)
+
+	sdoClient := node.GetSDOClient(1)
+	if sdoClient == nil {
+		t.Fatal("SDOClient of channel 1 not found")
+	}
+	assert.Equal(t, uint32(0x645), sdoClient.RXCobID)
+	assert.Equal(t, uint32(0x5C5), sdoClient.TXCobID)
+
+	data, err := sdoClient.Read(0x1000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x92, 0x01, 0x02, 0x00}, data)
+
+	assert.Nil(t, node.GetSDOClient(2))
+	assert.Equal(t, node.SDOClient, node.GetSDOClient(0))
+}
+
+func TestLocalNode_SDOClientChannels(t *testing.T) {
+	dic := NewDicObjectDic()
+	dic.AddObject(getSDOParameterRecord(0x1280, "0x601", "0x581", "1"))
+	dic.AddObject(getSDOParameterRecord(0x1281, "0x80000602", "0x582", "2"))
+	dic.AddObject(getSDOParameterRecord(0x1283, "0x623", "0x5A3", "3"))
+
+	localNode := NewLocalNode(10, nil, dic)
+	localNode.Init()
+
+	assert.Equal(t, []SDOChannel{
+		{Channel: 0, RXCobID: 0x601, TXCobID: 0x581, NodeID: 1},
+		{Channel: 3, RXCobID: 0x623, TXCobID: 0x5A3, NodeID: 3},
+	}, localNode.SDOClientChannels())
+}
+
+func TestLocalNode_AdditionalSDOServerWrites(t *testing.T) {
+	network := getLoopbackNetwork(t)
+
+	dic := getLocalObjectDic()
+	dic.AddObject(getSDOParameterRecord(0x1201, "$NODEID+0x640", "$NODEID+0x5C0", "0"))
+	dic.AddObject(&DicVariable{Index: 0x1015, Name: "Inhibit time EMCY", DataType: Unsigned16, AccessType: "rw", Default: []byte("0")})
+
+	localNode, err := network.AddLocalNode(NewLocalNode(5, nil, nil), dic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+	sdoClient := node.AddSDOClient(1, 0x645, 0x5C5)
+
+	// Producers are reconfigured by writes through additional servers
+	assert.NoError(t, sdoClient.WriteU16(0x1015, 0, 10))
+	localNode.EmcyProducer.Lock()
+	assert.Equal(t, time.Millisecond, localNode.EmcyProducer.InhibitTime)
+	localNode.EmcyProducer.Unlock()
+}
@@ -0,0 +1,180 @@
+package canopen
+
+import "time"
+
+// SDOBackoffFunc returns the timeout of the next try, from the timeout of the previous one
+type SDOBackoffFunc func(timeout time.Duration) time.Duration
+
+// SDOBackoffDouble double the timeout on each retry
+func SDOBackoffDouble(timeout time.Duration) time.Duration {
+	return timeout * 2
+}
+
+// SDOBackoffConstant keep the same timeout on each retry
+func SDOBackoffConstant(timeout time.Duration) time.Duration {
+	return timeout
+}
+
+// SDOClientOptions configure timeouts and retries of sdo requests
+type SDOClientOptions struct {
+	// Timeout of the first try of a request
+	Timeout time.Duration
+
+	// Retries is the number of tries after the first one, on timeout
+	Retries int
+
+	// Backoff compute the timeout of each retry. SDOBackoffDouble if nil
+	Backoff SDOBackoffFunc
+
+	// RetryOnlyOnInitiate retry only initiate requests. Segments can't be sent
+	// twice safely, as the server would take them for the next ones.
+	// False by default, segments are retried as initiate requests.
+	RetryOnlyOnInitiate bool
+}
+
+// DefaultSDOClientOptions returns the options used by new sdo clients
+func DefaultSDOClientOptions() SDOClientOptions {
+	return SDOClientOptions{
+		Timeout: sdoDefaultTimeout,
+		Retries: 3,
+		Backoff: SDOBackoffDouble,
+	}
+}
+
+// forRequest returns options to use for an initiate request or a segment request
+func (options SDOClientOptions) forRequest(initiate bool) SDOClientOptions {
+	if options.Timeout <= 0 {
+		options.Timeout = sdoDefaultTimeout
+	}
+
+	if options.Retries < 0 {
+		options.Retries = 0
+	}
+
+	if options.Backoff == nil {
+		options.Backoff = SDOBackoffDouble
+	}
+
+	if !initiate && options.RetryOnlyOnInitiate {
+		options.Retries = 0
+	}
+
+	return options
+}
+
+// SDOTransferOptions configure a single SDO upload or download
+type SDOTransferOptions struct {
+	// Block use block transfer, with fallback to segmented transfer
+	// when the server does not support it
+	Block bool
+
+	// BlockSize is the number of segments per block requested on block upload (1..127).
+	// On block download, the block size is given by the server.
+	BlockSize uint8
+
+	// CRC request crc generation and verification on block transfer
+	CRC bool
+
+	// Progress is called after each transfer of ReadMany and WriteMany
+	Progress SDOProgressFunc
+
+	// clientOptions override the options of the client for this transfer
+	clientOptions []func(*SDOClientOptions)
+}
+
+// SDOOption is a functional option applied to SDOTransferOptions
+type SDOOption func(*SDOTransferOptions)
+
+// NewSDOTransferOptions return SDOTransferOptions with defaults values, updated by opts
+func NewSDOTransferOptions(opts ...SDOOption) *SDOTransferOptions {
+	options := &SDOTransferOptions{
+		BlockSize: SDOBlockMaxSize,
+		CRC:       true,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// ClientOptions returns options of the client updated with overrides of the transfer
+func (options *SDOTransferOptions) ClientOptions(clientOptions SDOClientOptions) SDOClientOptions {
+	for _, opt := range options.clientOptions {
+		opt(&clientOptions)
+	}
+
+	return clientOptions
+}
+
+// WithBlockTransfer enable block transfer with given block size.
+// If blockSize is 0, the maximum block size is used
+func WithBlockTransfer(blockSize uint8) SDOOption {
+	return func(options *SDOTransferOptions) {
+		options.Block = true
+
+		if blockSize != 0 {
+			options.BlockSize = blockSize
+		}
+	}
+}
+
+// WithBlockCRC enable or disable crc on block transfer
+func WithBlockCRC(crc bool) SDOOption {
+	return func(options *SDOTransferOptions) {
+		options.CRC = crc
+	}
+}
+
+// WithClientOptions replace the options of the client for the transfer
+func WithClientOptions(clientOptions SDOClientOptions) SDOOption {
+	return func(options *SDOTransferOptions) {
+		options.clientOptions = append(options.clientOptions, func(o *SDOClientOptions) {
+			*o = clientOptions
+		})
+	}
+}
+
+// WithTimeout override the timeout of the client for the transfer
+func WithTimeout(timeout time.Duration) SDOOption {
+	return func(options *SDOTransferOptions) {
+		options.clientOptions = append(options.clientOptions, func(o *SDOClientOptions) {
+			o.Timeout = timeout
+		})
+	}
+}
+
+// WithRetries override the number of retries of the client for the transfer
+func WithRetries(retries int) SDOOption {
+	return func(options *SDOTransferOptions) {
+		options.clientOptions = append(options.clientOptions, func(o *SDOClientOptions) {
+			o.Retries = retries
+		})
+	}
+}
+
+// WithBackoff override the backoff strategy of the client for the transfer
+func WithBackoff(backoff SDOBackoffFunc) SDOOption {
+	return func(options *SDOTransferOptions) {
+		options.clientOptions = append(options.clientOptions, func(o *SDOClientOptions) {
+			o.Backoff = backoff
+		})
+	}
+}
+
+// WithRetryOnlyOnInitiate override the retry policy of segments of the client for the transfer
+func WithRetryOnlyOnInitiate(retryOnlyOnInitiate bool) SDOOption {
+	return func(options *SDOTransferOptions) {
+		options.clientOptions = append(options.clientOptions, func(o *SDOClientOptions) {
+			o.RetryOnlyOnInitiate = retryOnlyOnInitiate
+		})
+	}
+}
+
+// WithProgress set the function called after each transfer of ReadMany and WriteMany
+func WithProgress(progress SDOProgressFunc) SDOOption {
+	return func(options *SDOTransferOptions) {
+		options.Progress = progress
+	}
+}
@@ -0,0 +1,65 @@
+package canopen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getNodeWithoutReadResponse() *nodeMock {
+	node := &nodeMock{
+		id:      0,
+		network: networkMock{},
+	}
+	node.On("Send", uint32(0x600), []byte{0x40, 0xE8, 0x03, 0x02, 0x00, 0x00, 0x00, 0x00}).Return(nil, []send_response{})
+	return node
+}
+
+func TestSDOClient_Options(t *testing.T) {
+	node := getNodeWithoutReadResponse()
+	sdoClient := NewSDOClient(node)
+	sdoClient.Options = SDOClientOptions{Timeout: 20 * time.Millisecond}
+
+	start := time.Now()
+	_, err := sdoClient.Read(0x3E8, 0x02)
+	assert.ErrorIs(t, err, ErrSDOTimeout)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	node.AssertNumberOfCalls(t, "Send", 1)
+
+	// Override options of the client
+	timeouts := []time.Duration{}
+	backoff := func(timeout time.Duration) time.Duration {
+		timeouts = append(timeouts, timeout)
+		return timeout + 10*time.Millisecond
+	}
+
+	_, err = sdoClient.Read(0x3E8, 0x02, WithTimeout(10*time.Millisecond), WithRetries(2), WithBackoff(backoff))
+	assert.ErrorIs(t, err, ErrSDOTimeout)
+	node.AssertNumberOfCalls(t, "Send", 4)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}, timeouts)
+
+	// Options of the client are unchanged
+	assert.Equal(t, SDOClientOptions{Timeout: 20 * time.Millisecond}, sdoClient.Options)
+}
+
+func TestSDOClientOptions_ForRequest(t *testing.T) {
+	options := DefaultSDOClientOptions()
+	assert.Equal(t, 3, options.forRequest(true).Retries)
+	assert.Equal(t, 3, options.forRequest(false).Retries)
+
+	options.RetryOnlyOnInitiate = true
+	assert.Equal(t, 3, options.forRequest(true).Retries)
+	assert.Equal(t, 0, options.forRequest(false).Retries)
+
+	options = SDOClientOptions{Retries: -1}.forRequest(true)
+	assert.Equal(t, sdoDefaultTimeout, options.Timeout)
+	assert.Equal(t, 0, options.Retries)
+	assert.Equal(t, 2*time.Second, options.Backoff(time.Second))
+
+	transferOptions := NewSDOTransferOptions(WithClientOptions(SDOClientOptions{Timeout: time.Second}), WithRetries(5))
+	clientOptions := transferOptions.ClientOptions(DefaultSDOClientOptions())
+	assert.Equal(t, time.Second, clientOptions.Timeout)
+	assert.Equal(t, 5, clientOptions.Retries)
+	assert.False(t, clientOptions.RetryOnlyOnInitiate)
+}
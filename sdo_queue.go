@@ -0,0 +1,64 @@
+package canopen
+
+import (
+	"context"
+	"sync"
+)
+
+// sdoTransactionQueue serialize sdo transactions of a client.
+// Waiting transactions are started in arrival order.
+type sdoTransactionQueue struct {
+	mutex   sync.Mutex
+	busy    bool
+	waiters []chan struct{}
+}
+
+// acquire wait until every previous transaction is done, or ctx is done
+func (queue *sdoTransactionQueue) acquire(ctx context.Context) error {
+	queue.mutex.Lock()
+	if !queue.busy {
+		queue.busy = true
+		queue.mutex.Unlock()
+		return nil
+	}
+
+	waiter := make(chan struct{})
+	queue.waiters = append(queue.waiters, waiter)
+	queue.mutex.Unlock()
+
+	select {
+	case <-waiter:
+		return nil
+	case <-ctx.Done():
+	}
+
+	queue.mutex.Lock()
+	for idx, w := range queue.waiters {
+		if w == waiter {
+			queue.waiters = append(queue.waiters[:idx], queue.waiters[idx+1:]...)
+			queue.mutex.Unlock()
+			return ctx.Err()
+		}
+	}
+	queue.mutex.Unlock()
+
+	// The turn was given while ctx was done, pass it to the next one
+	queue.release()
+
+	return ctx.Err()
+}
+
+// release start the next waiting transaction
+func (queue *sdoTransactionQueue) release() {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	if len(queue.waiters) == 0 {
+		queue.busy = false
+		return
+	}
+
+	waiter := queue.waiters[0]
+	queue.waiters = queue.waiters[1:]
+	close(waiter)
+}
@@ -0,0 +1,104 @@
+package canopen
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSDOTransactionQueue_Order(t *testing.T) {
+	queue := &sdoTransactionQueue{}
+	assert.NoError(t, queue.acquire(context.Background()))
+
+	order := make(chan int, 5)
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, queue.acquire(context.Background()))
+			order <- i
+			queue.release()
+		}(i)
+
+		// Wait the goroutine to be queued
+		for {
+			queue.mutex.Lock()
+			n := len(queue.waiters)
+			queue.mutex.Unlock()
+			if n == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	queue.release()
+	wg.Wait()
+	close(order)
+
+	expected := 0
+	for i := range order {
+		assert.Equal(t, expected, i)
+		expected++
+	}
+	assert.False(t, queue.busy)
+}
+
+func TestSDOTransactionQueue_Context(t *testing.T) {
+	queue := &sdoTransactionQueue{}
+	assert.NoError(t, queue.acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, queue.acquire(ctx), context.DeadlineExceeded)
+	assert.Empty(t, queue.waiters)
+
+	queue.release()
+	assert.False(t, queue.busy)
+	assert.NoError(t, queue.acquire(context.Background()))
+}
+
+func TestSDOClient_Concurrent(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+
+	firmware := bytes.Repeat([]byte("firmware"), 50)
+	localNode.ObjectDic.FindIndex(0x2001).SetData(firmware)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				data, err := node.SDOClient.Read(0x1000, 0)
+				assert.NoError(t, err)
+				assert.Equal(t, []byte{0x92, 0x01, 0x02, 0x00}, data)
+
+				data, err = node.SDOClient.Read(0x2001, 0)
+				assert.NoError(t, err)
+				assert.Equal(t, firmware, data)
+
+				data, err = node.SDOClient.Read(0x2001, 0, WithBlockTransfer(16))
+				assert.NoError(t, err)
+				assert.Equal(t, firmware, data)
+
+				setpoint := make([]byte, 2)
+				binary.LittleEndian.PutUint16(setpoint, uint16(i*100+j))
+				assert.NoError(t, node.SDOClient.Write(0x2000, 0, false, setpoint))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	setpoint := binary.LittleEndian.Uint16(localNode.ObjectDic.FindIndex(0x2000).GetData())
+	assert.Equal(t, uint16(9), setpoint%100)
+}
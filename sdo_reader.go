@@ -1,10 +1,14 @@
 package canopen
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/jaster-prj/go-can"
+	"github.com/jaster-prj/go-canopen/utils"
 )
 
 type SDOReader struct {
@@ -15,6 +19,23 @@ type SDOReader struct {
 	Pos       int
 	Size      uint32
 	Data      []byte
+
+	// block is the state of the block upload in progress
+	block *sdoReaderBlock
+
+	// ctx of the transfer, see SetContext
+	ctx context.Context
+
+	// options of the transfer, see SetOptions
+	options *SDOClientOptions
+}
+
+type sdoReaderBlock struct {
+	framesChan  *NetworkFramesChan
+	size        uint8
+	crc         bool
+	crcValue    uint16
+	lastSegment []byte
 }
 
 func NewSDOReader(sdoClient *SDOClient, index uint16, subIndex uint8) *SDOReader {
@@ -26,6 +47,38 @@ func NewSDOReader(sdoClient *SDOClient, index uint16, subIndex uint8) *SDOReader
 	}
 }
 
+// SetContext set the context used for the transfer
+func (reader *SDOReader) SetContext(ctx context.Context) {
+	reader.ctx = ctx
+}
+
+// SetOptions set timeouts and retries used for the transfer, instead of the options of the client
+func (reader *SDOReader) SetOptions(options SDOClientOptions) {
+	reader.options = &options
+}
+
+// clientOptions returns the options to use for an initiate request or a segment request
+func (reader *SDOReader) clientOptions(initiate bool) SDOClientOptions {
+	if reader.options != nil {
+		return reader.options.forRequest(initiate)
+	}
+
+	return reader.SDOClient.GetOptions().forRequest(initiate)
+}
+
+// send request and wait for response, with the options of the transfer
+func (reader *SDOReader) send(req []byte, expectFunc networkFramesChanFilterFunc, initiate bool) (*can.Frame, error) {
+	return reader.SDOClient.SendOptions(reader.context(), req, expectFunc, reader.clientOptions(initiate))
+}
+
+func (reader *SDOReader) context() context.Context {
+	if reader.ctx == nil {
+		return context.Background()
+	}
+
+	return reader.ctx
+}
+
 // buildRequestUploadBuf working
 func (reader *SDOReader) buildRequestUploadBuf() []byte {
 	buf := make([]byte, 8) // 8 len is important
@@ -51,6 +104,10 @@ func (reader *SDOReader) buildRequestSegmentUploadBuf() []byte {
 // RequestUpload returns data if EXPEDITED, else nil
 func (reader *SDOReader) RequestUpload() ([]byte, error) {
 	expectFunc := func(frm *can.Frame) bool {
+		if isSDOAbortFrame(frm, reader.Index, reader.SubIndex) {
+			return true
+		}
+
 		resCommand := frm.Data[0]
 		resIndex := binary.LittleEndian.Uint16(frm.Data[1:])
 		resSubindex := frm.Data[3]
@@ -71,7 +128,7 @@ func (reader *SDOReader) RequestUpload() ([]byte, error) {
 		return true
 	}
 
-	frm, err := reader.SDOClient.Send(reader.buildRequestUploadBuf(), &expectFunc, nil, nil)
+	frm, err := reader.send(reader.buildRequestUploadBuf(), &expectFunc, true)
 	if err != nil {
 		return nil, err
 	}
@@ -113,11 +170,42 @@ func (reader *SDOReader) Read() (*can.Frame, error) {
 			return false
 		}
 
+		if isSDOAbortFrame(frm, reader.Index, reader.SubIndex) {
+			return true
+		}
+
 		resCommand := frm.Data[0]
 		return (resCommand & 0xE0) == SDOResponseSegmentUpload
 	}
 
-	return reader.SDOClient.Send(reader.buildRequestSegmentUploadBuf(), &expectFunc, nil, nil)
+	return reader.send(reader.buildRequestSegmentUploadBuf(), &expectFunc, false)
+}
+
+// ReadSegment upload next segment, and returns its data and true if it was the last one.
+// The transfer is aborted if the server does not answer.
+func (reader *SDOReader) ReadSegment() ([]byte, bool, error) {
+	frm, err := reader.Read()
+	if errors.Is(err, ErrSDOTimeout) {
+		reader.SDOClient.Abort(reader.Index, reader.SubIndex, SDOAbortTimeout)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	resCommand := frm.Data[0]
+	if (resCommand & SDOToggleBit) != reader.Toggle {
+		reader.SDOClient.Abort(reader.Index, reader.SubIndex, SDOAbortToggleBit)
+		return nil, false, errors.New("toggle bit mismatch")
+	}
+
+	length := int(7 - ((resCommand >> 1) & 0x7))
+	reader.Toggle ^= SDOToggleBit
+	reader.Pos += length
+
+	data := make([]byte, length)
+	copy(data, frm.Data[1:length+1])
+
+	return data, (resCommand & SDONoMoreData) != 0, nil
 }
 
 // ReadAll ..
@@ -134,30 +222,278 @@ func (reader *SDOReader) ReadAll() ([]byte, error) {
 
 	// Use Segmented upload
 	for {
-		frm, err := reader.Read()
+		segment, last, err := reader.ReadSegment()
 		if err != nil {
 			return nil, err
 		}
 
+		// Append data
+		reader.Data = append(reader.Data, segment...)
+
+		// If no more data
+		if last {
+			break
+		}
+
+		// Continue, read next segment
+	}
+
+	return reader.Data, nil
+}
+
+// buildRequestBlockUploadBuf
+func (reader *SDOReader) buildRequestBlockUploadBuf(blockSize uint8, crc bool) []byte {
+	buf := make([]byte, 8)
+
+	command := SDORequestBlockUpload | SDOBlockInitiate
+	if crc {
+		command |= SDOBlockCRC
+	}
+	buf[0] = command
+	binary.LittleEndian.PutUint16(buf[1:], reader.Index)
+	buf[3] = reader.SubIndex
+	buf[4] = blockSize
+
+	return buf
+}
+
+// buildBlockUploadAckBuf
+func (reader *SDOReader) buildBlockUploadAckBuf(ackSeq uint8, blockSize uint8) []byte {
+	buf := make([]byte, 8)
+
+	buf[0] = SDORequestBlockUpload | SDOBlockAck
+	buf[1] = ackSeq
+	buf[2] = blockSize
+
+	return buf
+}
+
+// RequestBlockUpload initiate a block upload, and returns true if server support crc
+func (reader *SDOReader) RequestBlockUpload(blockSize uint8, crc bool) (bool, error) {
+	expectFunc := func(frm *can.Frame) bool {
+		if isSDOAbortFrame(frm, reader.Index, reader.SubIndex) {
+			return true
+		}
+
 		resCommand := frm.Data[0]
-		if (resCommand & SDOToggleBit) != reader.Toggle {
-			return nil, errors.New("toggle bit mismatch")
+		resIndex := binary.LittleEndian.Uint16(frm.Data[1:])
+		resSubindex := frm.Data[3]
+
+		// Check response validity
+		if (resCommand & 0xE1) != (SDOResponseBlockUpload | SDOBlockInitiate) {
+			return false
 		}
 
-		length := int(7 - ((resCommand >> 1) & 0x7))
-		reader.Toggle ^= SDOToggleBit
-		reader.Pos += length
+		return resIndex == reader.Index && resSubindex == reader.SubIndex
+	}
 
-		// Append data
-		reader.Data = append(reader.Data, frm.Data[1:length+1]...)
+	frm, err := reader.send(reader.buildRequestBlockUploadBuf(blockSize, crc), &expectFunc, true)
+	if err != nil {
+		var abortErr *SDOAbortError
+		if errors.As(err, &abortErr) && abortErr.Code == SDOAbortCommandSpecifier {
+			return false, fmt.Errorf("%w: %w", ErrSDOBlockNotSupported, err)
+		}
 
-		// If no more data
-		if (resCommand & SDONoMoreData) != 0 {
+		return false, err
+	}
+
+	resCommand := frm.Data[0]
+	if (resCommand & SDOBlockSizeSpecified) != 0 {
+		reader.Size = binary.LittleEndian.Uint32(frm.Data[4:])
+	}
+
+	return (resCommand & SDOBlockCRC) != 0, nil
+}
+
+// StartBlockUpload initiate a block upload, and start receiving segments
+func (reader *SDOReader) StartBlockUpload(blockSize uint8, crc bool) error {
+	if blockSize == 0 || blockSize > SDOBlockMaxSize {
+		return fmt.Errorf("invalid block size %d", blockSize)
+	}
+
+	serverCRC, err := reader.RequestBlockUpload(blockSize, crc)
+	if err != nil {
+		return err
+	}
+
+	// Listen for all server segments until the end of the transfer
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == reader.SDOClient.TXCobID
+	}
+	framesChan := reader.SDOClient.Node.AcquireFramesChanFromNetwork(&filterFunc)
+
+	reader.block = &sdoReaderBlock{
+		framesChan: framesChan,
+		size:       blockSize,
+		crc:        crc && serverCRC,
+	}
+
+	// Start upload
+	startBuf := make([]byte, 8)
+	startBuf[0] = SDORequestBlockUpload | SDOBlockStartUpload
+	if err := reader.SDOClient.SendRequest(startBuf); err != nil {
+		reader.releaseBlock()
+		return err
+	}
+
+	return nil
+}
+
+// ReadBlock receive the next block, and returns its data and true if the transfer is done.
+// Missing or out of order segments are requested again from the last acknowledged segment.
+func (reader *SDOReader) ReadBlock() ([]byte, bool, error) {
+	block := reader.block
+	if block == nil {
+		return nil, false, errors.New("no block upload in progress")
+	}
+
+	data := []byte{}
+	ackSeq := uint8(0)
+	done := false
+
+	for {
+		frm, err := reader.waitFrame(block.framesChan)
+		if err != nil {
+			reader.releaseBlock()
+			return nil, false, err
+		}
+
+		resCommand := frm.Data[0]
+		seq := resCommand & 0x7F
+		last := (resCommand & SDOBlockNoMoreData) != 0
+
+		// Only accept segments in sequence, others will be sent again.
+		// Last segment is kept until the number of bytes without data is known.
+		if seq == ackSeq+1 {
+			ackSeq = seq
+			done = last
+
+			if last {
+				block.lastSegment = append([]byte{}, frm.Data[1:8]...)
+			} else {
+				data = append(data, frm.Data[1:8]...)
+			}
+		}
+
+		// Acknowledge at the end of each block
+		if seq == block.size || last {
+			if err := reader.SDOClient.SendRequest(reader.buildBlockUploadAckBuf(ackSeq, block.size)); err != nil {
+				reader.releaseBlock()
+				return nil, false, err
+			}
+
+			// A segment out of sequence may have been received, so the acknowledge
+			// ends the block, and data received is kept.
 			break
 		}
+	}
 
-		// Continue, read next segment
+	block.crcValue = utils.UpdateCRC16CCITT(block.crcValue, data)
+	reader.Pos += len(data)
+
+	if !done {
+		return data, false, nil
+	}
+
+	lastData, err := reader.endBlockUpload()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return append(data, lastData...), true, nil
+}
+
+// endBlockUpload wait end of block upload, and returns data of last segment
+func (reader *SDOReader) endBlockUpload() ([]byte, error) {
+	block := reader.block
+	defer reader.releaseBlock()
+
+	var endFrm *can.Frame
+	for endFrm == nil {
+		frm, err := reader.waitFrame(block.framesChan)
+		if err != nil {
+			return nil, err
+		}
+
+		if (frm.Data[0] & 0xE3) == (SDOResponseBlockUpload | SDOBlockEnd) {
+			endFrm = frm
+		}
+	}
+
+	// Remove bytes from last segment which do not contain data
+	length := int(7 - ((endFrm.Data[0] >> 2) & 0x7))
+	data := block.lastSegment[:length]
+	reader.Pos += length
+
+	if reader.Size != 0 && int(reader.Size) != reader.Pos {
+		reader.SDOClient.Abort(reader.Index, reader.SubIndex, SDOAbortTypeLength)
+		return nil, fmt.Errorf("size mismatch, expected %d bytes, received %d", reader.Size, reader.Pos)
+	}
+
+	if block.crc {
+		if binary.LittleEndian.Uint16(endFrm.Data[1:]) != utils.UpdateCRC16CCITT(block.crcValue, data) {
+			reader.SDOClient.Abort(reader.Index, reader.SubIndex, SDOAbortCRC)
+			return nil, errors.New("crc mismatch")
+		}
+	}
+
+	endBuf := make([]byte, 8)
+	endBuf[0] = SDORequestBlockUpload | SDOBlockEnd
+	if err := reader.SDOClient.SendRequest(endBuf); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// releaseBlock stop receiving segments
+func (reader *SDOReader) releaseBlock() {
+	if reader.block == nil {
+		return
+	}
+
+	reader.SDOClient.Node.ReleaseFramesChanFromNetwork(reader.block.framesChan.ID)
+	reader.block = nil
+}
+
+// ReadAllBlock upload data using block transfer
+func (reader *SDOReader) ReadAllBlock(blockSize uint8, crc bool) ([]byte, error) {
+	if err := reader.StartBlockUpload(blockSize, crc); err != nil {
+		return nil, err
+	}
+
+	for {
+		data, done, err := reader.ReadBlock()
+		if err != nil {
+			return nil, err
+		}
+
+		reader.Data = append(reader.Data, data...)
+
+		if done {
+			break
+		}
 	}
 
 	return reader.Data, nil
 }
+
+// waitFrame from framesChan, and abort transfer on timeout
+func (reader *SDOReader) waitFrame(framesChan *NetworkFramesChan) (*can.Frame, error) {
+	timer := time.NewTimer(reader.clientOptions(false).Timeout)
+	defer timer.Stop()
+
+	select {
+	case <-reader.context().Done():
+		return nil, reader.context().Err()
+	case <-timer.C:
+		reader.SDOClient.Abort(reader.Index, reader.SubIndex, SDOAbortTimeout)
+		return nil, ErrSDOTimeout
+	case frm := <-framesChan.C:
+		if abortErr := newSDOAbortErrorFromFrame(frm); abortErr != nil {
+			return nil, abortErr
+		}
+
+		return frm, nil
+	}
+}
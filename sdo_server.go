@@ -0,0 +1,707 @@
+package canopen
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/jaster-prj/go-canopen/utils"
+)
+
+// SDOServerReadFunc is called on upload of an object, and returns the object data
+type SDOServerReadFunc func(index uint16, subIndex uint8) ([]byte, error)
+
+// SDOServerWriteFunc is called on download of an object, before data is stored in the object dictionary
+type SDOServerWriteFunc func(index uint16, subIndex uint8, data []byte) error
+
+const (
+	sdoServerIdle = iota
+	sdoServerDownloadSegment
+	sdoServerUploadSegment
+	sdoServerBlockDownload
+	sdoServerBlockDownloadEnd
+	sdoServerBlockUploadInitiated
+	sdoServerBlockUpload
+)
+
+// sdoServerTransfer is the state of the transfer in progress
+type sdoServerTransfer struct {
+	State    int
+	Index    uint16
+	SubIndex uint8
+	Variable *DicVariable
+	Toggle   uint8
+	Size     *uint32
+	Data     []byte
+
+	// Block transfer only
+	CRC         bool
+	BlockSize   uint8
+	BlockStart  int
+	Segment     int
+	ExpectedSeq uint8
+	Done        bool
+}
+
+// SDOServer answer SDO requests from clients, with the local object dictionary
+type SDOServer struct {
+	sync.Mutex
+
+	Node      INode
+	ObjectDic *DicObjectDic
+	RXCobID   uint32
+	TXCobID   uint32
+
+	// BlockSize is the number of segments per block requested on block download
+	BlockSize uint8
+
+	readFuncs  map[uint32]SDOServerReadFunc
+	writeFuncs map[uint32]SDOServerWriteFunc
+
+	transfer sdoServerTransfer
+
+	listening bool
+	stopChan  chan bool
+	// doneChan is closed once the listening goroutine returned
+	doneChan chan bool
+
+	// networkFramesChanID is used to store and later close the network frames channel
+	networkFramesChanID *string
+}
+
+// NewSDOServer return a new SDOServer for node, serving objectDic on the default sdo channel
+func NewSDOServer(node INode, objectDic *DicObjectDic) *SDOServer {
+	return NewSDOServerWithCobIDs(node, objectDic, uint32(0x600+node.GetId()), uint32(0x580+node.GetId()))
+}
+
+// NewSDOServerWithCobIDs return a new SDOServer for node, receiving requests with rxCobID,
+// and sending responses with txCobID
+func NewSDOServerWithCobIDs(node INode, objectDic *DicObjectDic, rxCobID uint32, txCobID uint32) *SDOServer {
+	return &SDOServer{
+		Node:       node,
+		ObjectDic:  objectDic,
+		RXCobID:    rxCobID,
+		TXCobID:    txCobID,
+		BlockSize:  SDOBlockMaxSize,
+		readFuncs:  map[uint32]SDOServerReadFunc{},
+		writeFuncs: map[uint32]SDOServerWriteFunc{},
+	}
+}
+
+func sdoServerFuncKey(index uint16, subIndex uint8) uint32 {
+	return uint32(index)<<8 | uint32(subIndex)
+}
+
+// OnRead set the function called on upload of index / subIndex
+func (server *SDOServer) OnRead(index uint16, subIndex uint8, readFunc SDOServerReadFunc) {
+	server.Lock()
+	defer server.Unlock()
+
+	server.readFuncs[sdoServerFuncKey(index, subIndex)] = readFunc
+}
+
+// OnWrite set the function called on download of index / subIndex
+func (server *SDOServer) OnWrite(index uint16, subIndex uint8, writeFunc SDOServerWriteFunc) {
+	server.Lock()
+	defer server.Unlock()
+
+	server.writeFuncs[sdoServerFuncKey(index, subIndex)] = writeFunc
+}
+
+// Listen for client requests on network
+func (server *SDOServer) Listen() error {
+	server.Lock()
+	defer server.Unlock()
+
+	if server.listening {
+		return nil
+	}
+
+	rxCobID := server.RXCobID
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == rxCobID
+	}
+
+	framesChan := server.Node.AcquireFramesChanFromNetwork(&filterFunc)
+	if framesChan == nil {
+		return errors.New("no network defined")
+	}
+
+	server.listening = true
+	server.stopChan = make(chan bool, 1)
+	server.doneChan = make(chan bool)
+	server.networkFramesChanID = &framesChan.ID
+	server.transfer = sdoServerTransfer{}
+
+	go func(stopChan chan bool, doneChan chan bool) {
+		defer close(doneChan)
+
+		for {
+			select {
+			case <-stopChan:
+				// Stop goroutine
+				return
+			case frm, ok := <-framesChan.C:
+				if !ok {
+					return
+				}
+				server.handleFrame(frm)
+			}
+		}
+	}(server.stopChan, server.doneChan)
+
+	return nil
+}
+
+// Unlisten for client requests on network, and wait the request being handled
+func (server *SDOServer) Unlisten() error {
+	server.Lock()
+
+	if !server.listening {
+		server.Unlock()
+		return errors.New("not listening")
+	}
+
+	server.stopChan <- true
+	server.Node.ReleaseFramesChanFromNetwork(*server.networkFramesChanID)
+
+	server.networkFramesChanID = nil
+	server.listening = false
+	doneChan := server.doneChan
+	server.Unlock()
+
+	// Out of the lock taken by the request handlers
+	<-doneChan
+
+	return nil
+}
+
+// setCobIDs change the cob-ids of requests and responses, the server must not be listening
+func (server *SDOServer) setCobIDs(rxCobID uint32, txCobID uint32) {
+	server.Lock()
+	defer server.Unlock()
+
+	server.RXCobID = rxCobID
+	server.TXCobID = txCobID
+}
+
+// send a response to the client
+func (server *SDOServer) send(buf []byte) {
+	server.Lock()
+	txCobID := server.TXCobID
+	server.Unlock()
+
+	server.Node.Send(txCobID, buf)
+}
+
+// abort current transfer
+func (server *SDOServer) abort(index uint16, subIndex uint8, code uint32) {
+	server.transfer = sdoServerTransfer{}
+	server.send(buildSDOAbortBuf(index, subIndex, code))
+}
+
+// abortWithError abort current transfer, with abort code from err
+func (server *SDOServer) abortWithError(index uint16, subIndex uint8, err error) {
+	code := SDOAbortGeneral
+
+	var abortErr *SDOAbortError
+	if errors.As(err, &abortErr) {
+		code = abortErr.Code
+	}
+
+	server.abort(index, subIndex, code)
+}
+
+// buildResponseBuf with command and index / subIndex of current transfer
+func (server *SDOServer) buildResponseBuf(command uint8) []byte {
+	buf := make([]byte, 8)
+
+	buf[0] = command
+	binary.LittleEndian.PutUint16(buf[1:], server.transfer.Index)
+	buf[3] = server.transfer.SubIndex
+
+	return buf
+}
+
+// handleFrame dispatch client request to handlers
+func (server *SDOServer) handleFrame(frm *can.Frame) {
+	command := frm.Data[0]
+
+	// During block download, all frames are segments, except abort
+	if server.transfer.State == sdoServerBlockDownload && command != SDOAbort {
+		server.handleBlockDownloadSegment(frm)
+		return
+	}
+
+	switch command & 0xE0 {
+	case SDORequestDownload:
+		server.handleRequestDownload(frm)
+	case SDORequestSegmentDownload:
+		server.handleRequestSegmentDownload(frm)
+	case SDORequestUpload:
+		server.handleRequestUpload(frm)
+	case SDORequestSegmentUpload:
+		server.handleRequestSegmentUpload(frm)
+	case SDORequestBlockDownload:
+		server.handleRequestBlockDownload(frm)
+	case SDORequestBlockUpload:
+		server.handleRequestBlockUpload(frm)
+	case SDOAbort:
+		server.transfer = sdoServerTransfer{}
+	default:
+		server.abort(
+			binary.LittleEndian.Uint16(frm.Data[1:]),
+			frm.Data[3],
+			SDOAbortCommandSpecifier,
+		)
+	}
+}
+
+// findVariable in object dictionary, and check access type
+func (server *SDOServer) findVariable(index uint16, subIndex uint8, write bool) (*DicVariable, *SDOAbortError) {
+	if server.ObjectDic == nil {
+		return nil, NewSDOAbortError(index, subIndex, SDOAbortNoObjectDic)
+	}
+
+	object := server.ObjectDic.FindIndex(index)
+	if object == nil {
+		return nil, NewSDOAbortError(index, subIndex, SDOAbortObjectDoesNotExist)
+	}
+
+	if !object.IsDicVariable() {
+		object = object.FindIndex(uint16(subIndex))
+	} else if subIndex != 0 {
+		object = nil
+	}
+
+	variable, ok := object.(*DicVariable)
+	if !ok || variable == nil {
+		return nil, NewSDOAbortError(index, subIndex, SDOAbortSubIndexDoesNotExist)
+	}
+
+	switch variable.AccessType {
+	case "ro", "const":
+		if write {
+			return nil, NewSDOAbortError(index, subIndex, SDOAbortReadOnly)
+		}
+	case "wo":
+		if !write {
+			return nil, NewSDOAbortError(index, subIndex, SDOAbortWriteOnly)
+		}
+	}
+
+	return variable, nil
+}
+
+// readVariable data, from read func if any
+func (server *SDOServer) readVariable(index uint16, subIndex uint8) ([]byte, error) {
+	variable, abortErr := server.findVariable(index, subIndex, false)
+	if abortErr != nil {
+		return nil, abortErr
+	}
+
+	server.Lock()
+	readFunc, ok := server.readFuncs[sdoServerFuncKey(index, subIndex)]
+	server.Unlock()
+
+	if ok {
+		return readFunc(index, subIndex)
+	}
+
+	server.ObjectDic.Lock()
+	defer server.ObjectDic.Unlock()
+
+	return append([]byte{}, variable.GetData()...), nil
+}
+
+// writeVariable data, after calling write func if any
+func (server *SDOServer) writeVariable(variable *DicVariable, data []byte) error {
+	index := variable.GetIndex()
+	subIndex := variable.GetSubIndex()
+
+	// Check length of fixed size data types
+	if !IsDataType(variable.DataType) {
+		size := variable.GetDataLen() / 8
+		if len(data) > size {
+			return NewSDOAbortError(index, subIndex, SDOAbortTypeLengthTooHigh)
+		}
+		if len(data) < size {
+			return NewSDOAbortError(index, subIndex, SDOAbortTypeLengthTooLow)
+		}
+	}
+
+	server.Lock()
+	writeFunc, ok := server.writeFuncs[sdoServerFuncKey(index, subIndex)]
+	server.Unlock()
+
+	if ok {
+		if err := writeFunc(index, subIndex, data); err != nil {
+			return err
+		}
+	}
+
+	server.ObjectDic.Lock()
+	variable.SetData(append([]byte{}, data...))
+	server.ObjectDic.Unlock()
+
+	return nil
+}
+
+// handleRequestDownload initiate expedited or segmented download
+func (server *SDOServer) handleRequestDownload(frm *can.Frame) {
+	command := frm.Data[0]
+	index := binary.LittleEndian.Uint16(frm.Data[1:])
+	subIndex := frm.Data[3]
+
+	variable, abortErr := server.findVariable(index, subIndex, true)
+	if abortErr != nil {
+		server.abort(index, subIndex, abortErr.Code)
+		return
+	}
+
+	server.transfer = sdoServerTransfer{Index: index, SubIndex: subIndex, Variable: variable}
+
+	// Expedited download
+	if (command & SDOExpedited) != 0 {
+		size := 4
+		if (command & SDOSizeSpecified) != 0 {
+			size = 4 - int((command>>2)&0x3)
+		} else if fixedSize := variable.GetDataLen() / 8; !IsDataType(variable.DataType) && fixedSize > 0 && fixedSize < size {
+			// Size not indicated, bytes after the size of the variable are unused
+			size = fixedSize
+		}
+
+		if err := server.writeVariable(variable, frm.Data[4:4+size]); err != nil {
+			server.abortWithError(index, subIndex, err)
+			return
+		}
+
+		server.send(server.buildResponseBuf(SDOResponseDownload))
+		server.transfer = sdoServerTransfer{}
+		return
+	}
+
+	// Segmented download
+	if (command & SDOSizeSpecified) != 0 {
+		size := binary.LittleEndian.Uint32(frm.Data[4:])
+		server.transfer.Size = &size
+	}
+	server.transfer.State = sdoServerDownloadSegment
+	server.transfer.Data = []byte{}
+
+	server.send(server.buildResponseBuf(SDOResponseDownload))
+}
+
+// handleRequestSegmentDownload receive a download segment
+func (server *SDOServer) handleRequestSegmentDownload(frm *can.Frame) {
+	command := frm.Data[0]
+
+	if server.transfer.State != sdoServerDownloadSegment {
+		server.abort(server.transfer.Index, server.transfer.SubIndex, SDOAbortCommandSpecifier)
+		return
+	}
+
+	if (command & SDOToggleBit) != server.transfer.Toggle {
+		server.abort(server.transfer.Index, server.transfer.SubIndex, SDOAbortToggleBit)
+		return
+	}
+
+	length := int(7 - ((command >> 1) & 0x7))
+	server.transfer.Data = append(server.transfer.Data, frm.Data[1:length+1]...)
+
+	response := make([]byte, 8)
+	response[0] = SDOResponseSegmentDownload | server.transfer.Toggle
+	server.transfer.Toggle ^= SDOToggleBit
+
+	// Wait next segment
+	if (command & SDONoMoreData) == 0 {
+		server.send(response)
+		return
+	}
+
+	transfer := server.transfer
+	if transfer.Size != nil && int(*transfer.Size) != len(transfer.Data) {
+		server.abort(transfer.Index, transfer.SubIndex, SDOAbortTypeLength)
+		return
+	}
+
+	if err := server.writeVariable(transfer.Variable, transfer.Data); err != nil {
+		server.abortWithError(transfer.Index, transfer.SubIndex, err)
+		return
+	}
+
+	server.transfer = sdoServerTransfer{}
+	server.send(response)
+}
+
+// handleRequestUpload initiate expedited or segmented upload
+func (server *SDOServer) handleRequestUpload(frm *can.Frame) {
+	index := binary.LittleEndian.Uint16(frm.Data[1:])
+	subIndex := frm.Data[3]
+
+	data, err := server.readVariable(index, subIndex)
+	if err != nil {
+		server.abortWithError(index, subIndex, err)
+		return
+	}
+
+	server.transfer = sdoServerTransfer{Index: index, SubIndex: subIndex}
+
+	// Expedited upload
+	if len(data) > 0 && len(data) <= 4 {
+		response := server.buildResponseBuf(SDOResponseUpload | SDOExpedited | SDOSizeSpecified | (4-uint8(len(data)))<<2)
+		copy(response[4:], data)
+
+		server.transfer = sdoServerTransfer{}
+		server.send(response)
+		return
+	}
+
+	// Segmented upload
+	server.transfer.State = sdoServerUploadSegment
+	server.transfer.Data = data
+
+	response := server.buildResponseBuf(SDOResponseUpload | SDOSizeSpecified)
+	binary.LittleEndian.PutUint32(response[4:], uint32(len(data)))
+
+	server.send(response)
+}
+
+// handleRequestSegmentUpload send next upload segment
+func (server *SDOServer) handleRequestSegmentUpload(frm *can.Frame) {
+	command := frm.Data[0]
+
+	if server.transfer.State != sdoServerUploadSegment {
+		server.abort(server.transfer.Index, server.transfer.SubIndex, SDOAbortCommandSpecifier)
+		return
+	}
+
+	if (command & SDOToggleBit) != server.transfer.Toggle {
+		server.abort(server.transfer.Index, server.transfer.SubIndex, SDOAbortToggleBit)
+		return
+	}
+
+	length := min(len(server.transfer.Data), 7)
+
+	response := make([]byte, 8)
+	response[0] = SDOResponseSegmentUpload | server.transfer.Toggle | (7-uint8(length))<<1
+	copy(response[1:], server.transfer.Data[:length])
+
+	server.transfer.Data = server.transfer.Data[length:]
+	server.transfer.Toggle ^= SDOToggleBit
+
+	if len(server.transfer.Data) == 0 {
+		response[0] |= SDONoMoreData
+		server.transfer = sdoServerTransfer{}
+	}
+
+	server.send(response)
+}
+
+// handleRequestBlockDownload handle initiate and end of block download
+func (server *SDOServer) handleRequestBlockDownload(frm *can.Frame) {
+	command := frm.Data[0]
+
+	// End block download
+	if (command & 0x3) == SDOBlockEnd {
+		transfer := server.transfer
+		if transfer.State != sdoServerBlockDownloadEnd {
+			server.abort(transfer.Index, transfer.SubIndex, SDOAbortCommandSpecifier)
+			return
+		}
+
+		// Remove bytes from last segment which do not contain data
+		length := len(transfer.Data) - int((command>>2)&0x7)
+		if length < 0 {
+			server.abort(transfer.Index, transfer.SubIndex, SDOAbortGeneral)
+			return
+		}
+		data := transfer.Data[:length]
+
+		if transfer.Size != nil && int(*transfer.Size) != len(data) {
+			server.abort(transfer.Index, transfer.SubIndex, SDOAbortTypeLength)
+			return
+		}
+
+		if transfer.CRC && binary.LittleEndian.Uint16(frm.Data[1:]) != utils.CRC16CCITT(data) {
+			server.abort(transfer.Index, transfer.SubIndex, SDOAbortCRC)
+			return
+		}
+
+		if err := server.writeVariable(transfer.Variable, data); err != nil {
+			server.abortWithError(transfer.Index, transfer.SubIndex, err)
+			return
+		}
+
+		server.transfer = sdoServerTransfer{}
+		server.send([]byte{SDOResponseBlockDownload | SDOBlockEnd, 0, 0, 0, 0, 0, 0, 0})
+		return
+	}
+
+	// Initiate block download
+	index := binary.LittleEndian.Uint16(frm.Data[1:])
+	subIndex := frm.Data[3]
+
+	variable, abortErr := server.findVariable(index, subIndex, true)
+	if abortErr != nil {
+		server.abort(index, subIndex, abortErr.Code)
+		return
+	}
+
+	server.transfer = sdoServerTransfer{
+		State:       sdoServerBlockDownload,
+		Index:       index,
+		SubIndex:    subIndex,
+		Variable:    variable,
+		Data:        []byte{},
+		CRC:         (command & SDOBlockCRC) != 0,
+		BlockSize:   server.BlockSize,
+		ExpectedSeq: 1,
+	}
+
+	if (command & SDOBlockSizeSpecified) != 0 {
+		size := binary.LittleEndian.Uint32(frm.Data[4:])
+		server.transfer.Size = &size
+	}
+
+	response := server.buildResponseBuf(SDOResponseBlockDownload | SDOBlockCRC | SDOBlockInitiate)
+	response[4] = server.transfer.BlockSize
+
+	server.send(response)
+}
+
+// handleBlockDownloadSegment receive a block download segment
+func (server *SDOServer) handleBlockDownloadSegment(frm *can.Frame) {
+	transfer := &server.transfer
+	seq := frm.Data[0] & 0x7F
+	last := (frm.Data[0] & SDOBlockNoMoreData) != 0
+
+	// Only accept segments in sequence, others will be sent again
+	if seq == transfer.ExpectedSeq {
+		transfer.Data = append(transfer.Data, frm.Data[1:8]...)
+		transfer.ExpectedSeq++
+		transfer.Done = last
+	}
+
+	// Acknowledge at the end of each block
+	if seq != transfer.BlockSize && !last {
+		return
+	}
+
+	response := make([]byte, 8)
+	response[0] = SDOResponseBlockDownload | SDOBlockAck
+	response[1] = transfer.ExpectedSeq - 1
+	response[2] = transfer.BlockSize
+
+	transfer.ExpectedSeq = 1
+	if transfer.Done {
+		transfer.State = sdoServerBlockDownloadEnd
+	}
+
+	server.send(response)
+}
+
+// handleRequestBlockUpload handle initiate, start, acknowledge and end of block upload
+func (server *SDOServer) handleRequestBlockUpload(frm *can.Frame) {
+	command := frm.Data[0]
+	transfer := &server.transfer
+
+	switch command & 0x3 {
+	case SDOBlockInitiate:
+		index := binary.LittleEndian.Uint16(frm.Data[1:])
+		subIndex := frm.Data[3]
+
+		data, err := server.readVariable(index, subIndex)
+		if err != nil {
+			server.abortWithError(index, subIndex, err)
+			return
+		}
+
+		blockSize := frm.Data[4]
+		if blockSize == 0 || blockSize > SDOBlockMaxSize {
+			server.abort(index, subIndex, SDOAbortBlockSize)
+			return
+		}
+
+		server.transfer = sdoServerTransfer{
+			State:     sdoServerBlockUploadInitiated,
+			Index:     index,
+			SubIndex:  subIndex,
+			Data:      data,
+			CRC:       (command & SDOBlockCRC) != 0,
+			BlockSize: blockSize,
+		}
+
+		response := server.buildResponseBuf(SDOResponseBlockUpload | SDOBlockCRC | SDOBlockSizeSpecified | SDOBlockInitiate)
+		binary.LittleEndian.PutUint32(response[4:], uint32(len(data)))
+
+		server.send(response)
+	case SDOBlockStartUpload:
+		if transfer.State != sdoServerBlockUploadInitiated {
+			server.abort(transfer.Index, transfer.SubIndex, SDOAbortCommandSpecifier)
+			return
+		}
+
+		transfer.State = sdoServerBlockUpload
+		server.sendUploadBlock()
+	case SDOBlockAck:
+		if transfer.State != sdoServerBlockUpload {
+			server.abort(transfer.Index, transfer.SubIndex, SDOAbortCommandSpecifier)
+			return
+		}
+
+		ackSeq := int(frm.Data[1])
+		if ackSeq > transfer.Segment-transfer.BlockStart {
+			server.abort(transfer.Index, transfer.SubIndex, SDOAbortSequenceNumber)
+			return
+		}
+
+		// Continue from the last acknowledged segment
+		transfer.Segment = transfer.BlockStart + ackSeq
+
+		if frm.Data[2] != 0 && frm.Data[2] <= SDOBlockMaxSize {
+			transfer.BlockSize = frm.Data[2]
+		}
+
+		nofSegments := max(1, (len(transfer.Data)+6)/7)
+		if transfer.Segment < nofSegments {
+			server.sendUploadBlock()
+			return
+		}
+
+		// All segments acknowledged, end block upload
+		response := make([]byte, 8)
+		response[0] = SDOResponseBlockUpload | SDOBlockEnd | uint8(nofSegments*7-len(transfer.Data))<<2
+		if transfer.CRC {
+			binary.LittleEndian.PutUint16(response[1:], utils.CRC16CCITT(transfer.Data))
+		}
+
+		server.send(response)
+	case SDOBlockEnd:
+		server.transfer = sdoServerTransfer{}
+	}
+}
+
+// sendUploadBlock send segments of the next block
+func (server *SDOServer) sendUploadBlock() {
+	transfer := &server.transfer
+	nofSegments := max(1, (len(transfer.Data)+6)/7)
+	transfer.BlockStart = transfer.Segment
+
+	for seq := uint8(1); seq <= transfer.BlockSize && transfer.Segment < nofSegments; seq++ {
+		buf := make([]byte, 8)
+		buf[0] = seq
+
+		if transfer.Segment == nofSegments-1 {
+			buf[0] |= SDOBlockNoMoreData
+		}
+
+		start := transfer.Segment * 7
+		end := min(start+7, len(transfer.Data))
+		copy(buf[1:], transfer.Data[start:end])
+
+		transfer.Segment++
+		server.send(buf)
+	}
+}
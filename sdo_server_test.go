@@ -0,0 +1,193 @@
+package canopen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+func getLocalObjectDic() *DicObjectDic {
+	dic := NewDicObjectDic()
+	dic.AddObject(&DicVariable{Index: 0x1000, Name: "Device type", DataType: Unsigned32, AccessType: "ro", Default: []byte("0x00020192")})
+	dic.AddObject(&DicVariable{Index: 0x1001, Name: "Error register", DataType: Unsigned8, AccessType: "ro", Default: []byte("0")})
+
+	errorField := &DicArray{Index: 0x1003, Name: "Pre-defined error field"}
+	errorField.AddMember(&DicVariable{Index: 0x1003, SubIndex: 0, Name: "Number of errors", DataType: Unsigned8, AccessType: "rw", Default: []byte("0")})
+	for subIndex := uint8(1); subIndex <= 4; subIndex++ {
+		errorField.AddMember(&DicVariable{Index: 0x1003, SubIndex: subIndex, Name: fmt.Sprintf("Standard error field %d", subIndex), DataType: Unsigned32, AccessType: "ro", Default: []byte("0")})
+	}
+	dic.AddObject(errorField)
+
+	identity := &DicRecord{Index: 0x1018, Name: "Identity object"}
+	identity.AddMember(&DicVariable{Index: 0x1018, SubIndex: 0, Name: "Highest sub-index supported", DataType: Unsigned8, AccessType: "const", Default: []byte("4")})
+	identity.AddMember(&DicVariable{Index: 0x1018, SubIndex: 1, Name: "Vendor-ID", DataType: Unsigned32, AccessType: "ro", Default: []byte("0x00000123")})
+	identity.AddMember(&DicVariable{Index: 0x1018, SubIndex: 2, Name: "Product code", DataType: Unsigned32, AccessType: "ro", Default: []byte("0x00000456")})
+	identity.AddMember(&DicVariable{Index: 0x1018, SubIndex: 3, Name: "Revision number", DataType: Unsigned32, AccessType: "ro", Default: []byte("0x00010002")})
+	identity.AddMember(&DicVariable{Index: 0x1018, SubIndex: 4, Name: "Serial number", DataType: Unsigned32, AccessType: "ro", Default: []byte("1234")})
+	dic.AddObject(identity)
+
+	verify := &DicRecord{Index: 0x1020, Name: "Verify configuration"}
+	verify.AddMember(&DicVariable{Index: 0x1020, SubIndex: 0, Name: "Highest sub-index supported", DataType: Unsigned8, AccessType: "const", Default: []byte("2")})
+	verify.AddMember(&DicVariable{Index: 0x1020, SubIndex: 1, Name: "Configuration date", DataType: Unsigned32, AccessType: "rw", Default: []byte("0")})
+	verify.AddMember(&DicVariable{Index: 0x1020, SubIndex: 2, Name: "Configuration time", DataType: Unsigned32, AccessType: "rw", Default: []byte("0")})
+	dic.AddObject(verify)
+
+	dic.AddObject(&DicVariable{Index: 0x2000, Name: "Setpoint", DataType: Unsigned16, AccessType: "rw", Default: []byte("$NODEID+0x10")})
+	dic.AddObject(&DicVariable{Index: 0x2001, Name: "Firmware", DataType: Domain, AccessType: "rw"})
+	dic.AddObject(&DicVariable{Index: 0x2002, Name: "Command", DataType: Unsigned8, AccessType: "wo"})
+
+	record := &DicRecord{Index: 0x2003, Name: "Limits"}
+	record.AddMember(&DicVariable{Index: 0x2003, SubIndex: 0, Name: "Highest sub-index supported", DataType: Unsigned8, AccessType: "const", Default: []byte("1")})
+	record.AddMember(&DicVariable{Index: 0x2003, SubIndex: 1, Name: "Max", DataType: Integer32, AccessType: "rw", Default: []byte("-5")})
+	dic.AddObject(record)
+
+	dic.AddObject(&DicVariable{Index: 0x2004, Name: "Temperature", DataType: Real32, AccessType: "rw", Default: []byte("1.5")})
+	dic.AddObject(&DicVariable{Index: 0x2005, Name: "Label", DataType: VisibleString, AccessType: "rw", Default: []byte("pump")})
+
+	return dic
+}
+
+func getLocalAndRemoteNodes(t *testing.T) (*LocalNode, *Node) {
+	network := getLoopbackNetwork(t)
+
+	localNode, err := network.AddLocalNode(NewLocalNode(5, nil, nil), getLocalObjectDic())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+
+	return localNode, node
+}
+
+func assertSDOAbortCode(t *testing.T, err error, code uint32) {
+	t.Helper()
+
+	var abortErr *SDOAbortError
+	if !errors.As(err, &abortErr) {
+		t.Fatalf("error = %v, want SDOAbortError", err)
+	}
+	assert.Equal(t, code, abortErr.Code)
+}
+
+func TestSDOServer_Expedited(t *testing.T) {
+	_, node := getLocalAndRemoteNodes(t)
+
+	data, err := node.SDOClient.Read(0x1000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x92, 0x01, 0x02, 0x00}, data)
+
+	data, err = node.SDOClient.Read(0x2000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x15, 0x00}, data)
+
+	assert.NoError(t, node.SDOClient.Write(0x2000, 0, false, []byte{0x34, 0x12}))
+	data, err = node.SDOClient.Read(0x2000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x34, 0x12}, data)
+
+	data, err = node.SDOClient.Read(0x2003, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xFB, 0xFF, 0xFF, 0xFF}, data)
+
+	// Without size indicated, data is truncated to the size of the variable
+	expectFunc := func(frm *can.Frame) bool { return true }
+	_, err = node.SDOClient.Send([]byte{SDORequestDownload | SDOExpedited, 0x00, 0x20, 0x00, 0x78, 0x56, 0xAA, 0xBB}, &expectFunc, nil, nil)
+	assert.NoError(t, err)
+	data, err = node.SDOClient.Read(0x2000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x78, 0x56}, data)
+}
+
+func TestSDOServer_Aborts(t *testing.T) {
+	_, node := getLocalAndRemoteNodes(t)
+
+	_, err := node.SDOClient.Read(0x3000, 0)
+	assertSDOAbortCode(t, err, SDOAbortObjectDoesNotExist)
+
+	_, err = node.SDOClient.Read(0x2003, 2)
+	assertSDOAbortCode(t, err, SDOAbortSubIndexDoesNotExist)
+
+	_, err = node.SDOClient.Read(0x2002, 0)
+	assertSDOAbortCode(t, err, SDOAbortWriteOnly)
+
+	err = node.SDOClient.Write(0x1000, 0, false, []byte{0x00, 0x00, 0x00, 0x00})
+	assertSDOAbortCode(t, err, SDOAbortReadOnly)
+
+	err = node.SDOClient.Write(0x2003, 0, false, []byte{0x02})
+	assertSDOAbortCode(t, err, SDOAbortReadOnly)
+
+	err = node.SDOClient.Write(0x2000, 0, false, []byte{0x00, 0x00, 0x00, 0x00})
+	assertSDOAbortCode(t, err, SDOAbortTypeLengthTooHigh)
+
+	err = node.SDOClient.Write(0x2000, 0, false, []byte{0x00})
+	assertSDOAbortCode(t, err, SDOAbortTypeLengthTooLow)
+}
+
+func TestSDOServer_Segmented(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+
+	firmware := bytes.Repeat([]byte("firmware"), 20)
+	assert.NoError(t, node.SDOClient.Write(0x2001, 0, true, firmware))
+	assert.Equal(t, firmware, localNode.ObjectDic.FindIndex(0x2001).GetData())
+
+	data, err := node.SDOClient.Read(0x2001, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, firmware, data)
+
+	assert.NoError(t, node.SDOClient.Write(0x2001, 0, true, []byte{}))
+	data, err = node.SDOClient.Read(0x2001, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{}, data)
+}
+
+func TestSDOServer_Block(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+	localNode.SDOServer.BlockSize = 16
+
+	firmware := bytes.Repeat([]byte("firmware"), 200)
+	assert.NoError(t, node.SDOClient.Write(0x2001, 0, true, firmware, WithBlockTransfer(0)))
+	assert.Equal(t, firmware, localNode.ObjectDic.FindIndex(0x2001).GetData())
+
+	data, err := node.SDOClient.Read(0x2001, 0, WithBlockTransfer(10))
+	assert.NoError(t, err)
+	assert.Equal(t, firmware, data)
+
+	data, err = node.SDOClient.Read(0x2001, 0, WithBlockTransfer(0), WithBlockCRC(false))
+	assert.NoError(t, err)
+	assert.Equal(t, firmware, data)
+}
+
+func TestSDOServer_Callbacks(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+
+	localNode.SDOServer.OnRead(0x2000, 0, func(index uint16, subIndex uint8) ([]byte, error) {
+		return []byte{0xCD, 0xAB}, nil
+	})
+	localNode.SDOServer.OnWrite(0x2000, 0, func(index uint16, subIndex uint8, data []byte) error {
+		if data[1] > 0x10 {
+			return NewSDOAbortError(index, subIndex, SDOAbortValueTooHigh)
+		}
+		return nil
+	})
+	localNode.SDOServer.OnWrite(0x2002, 0, func(index uint16, subIndex uint8, data []byte) error {
+		return errors.New("device busy")
+	})
+
+	data, err := node.SDOClient.Read(0x2000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xCD, 0xAB}, data)
+
+	err = node.SDOClient.Write(0x2000, 0, false, []byte{0x00, 0x20})
+	assertSDOAbortCode(t, err, SDOAbortValueTooHigh)
+
+	assert.NoError(t, node.SDOClient.Write(0x2000, 0, false, []byte{0x00, 0x01}))
+	assert.Equal(t, []byte{0x00, 0x01}, localNode.ObjectDic.FindIndex(0x2000).GetData())
+
+	err = node.SDOClient.Write(0x2002, 0, false, []byte{0x01})
+	assertSDOAbortCode(t, err, SDOAbortGeneral)
+}
@@ -0,0 +1,302 @@
+package canopen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSDOStreamClosed is returned on use of a closed stream
+var ErrSDOStreamClosed = errors.New("sdo stream closed")
+
+// SDOStreamReader upload an object on demand, segment by segment or block by block
+type SDOStreamReader struct {
+	reader *SDOReader
+	block  bool
+	buf    []byte
+	done   bool
+	closed bool
+	queue  *sdoTransactionQueue
+}
+
+// OpenReader initiate the upload of index / subIndex, and returns an io.ReadCloser.
+// Closing the reader before the end of the transfer abort the transfer.
+// Others transactions of sdoClient are queued until the transfer is done or aborted.
+func (sdoClient *SDOClient) OpenReader(index uint16, subIndex uint8, opts ...SDOOption) (io.ReadCloser, error) {
+	return sdoClient.OpenReaderContext(context.Background(), index, subIndex, opts...)
+}
+
+// OpenReaderContext is OpenReader, waiting for others transactions and transferring until ctx is done.
+// If ctx is done during the transfer, the transfer is aborted.
+func (sdoClient *SDOClient) OpenReaderContext(ctx context.Context, index uint16, subIndex uint8, opts ...SDOOption) (io.ReadCloser, error) {
+	if err := sdoClient.queue.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	options := NewSDOTransferOptions(opts...)
+	stream := &SDOStreamReader{
+		reader: NewSDOReader(sdoClient, index, subIndex),
+		queue:  &sdoClient.queue,
+	}
+	stream.reader.SetContext(ctx)
+	stream.reader.SetOptions(options.ClientOptions(sdoClient.Options))
+
+	if options.Block {
+		err := stream.reader.StartBlockUpload(options.BlockSize, options.CRC)
+		if err == nil {
+			stream.block = true
+			return stream, nil
+		}
+
+		if !errors.Is(err, ErrSDOBlockNotSupported) {
+			sdoClient.queue.release()
+			return nil, sdoClient.abortOnContextError(index, subIndex, err)
+		}
+
+		// Fallback to segmented transfer
+		stream.reader = NewSDOReader(sdoClient, index, subIndex)
+		stream.reader.SetContext(ctx)
+		stream.reader.SetOptions(options.ClientOptions(sdoClient.Options))
+	}
+
+	data, err := stream.reader.RequestUpload()
+	if err != nil {
+		sdoClient.queue.release()
+		return nil, sdoClient.abortOnContextError(index, subIndex, err)
+	}
+
+	// If EXPEDITED, data is already there
+	if data != nil {
+		stream.buf = data
+		stream.finish()
+	}
+
+	return stream, nil
+}
+
+// Size of the object, if given by the server
+func (stream *SDOStreamReader) Size() uint32 {
+	return stream.reader.Size
+}
+
+// Read implement io.Reader
+func (stream *SDOStreamReader) Read(p []byte) (int, error) {
+	if stream.closed {
+		return 0, ErrSDOStreamClosed
+	}
+
+	for len(stream.buf) == 0 && !stream.done {
+		var data []byte
+		var err error
+
+		if stream.block {
+			data, stream.done, err = stream.reader.ReadBlock()
+		} else {
+			data, stream.done, err = stream.reader.ReadSegment()
+		}
+
+		if err != nil {
+			stream.finish()
+			return 0, stream.reader.SDOClient.abortOnContextError(stream.reader.Index, stream.reader.SubIndex, err)
+		}
+
+		stream.buf = data
+		if stream.done {
+			stream.finish()
+		}
+	}
+
+	if len(stream.buf) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, stream.buf)
+	stream.buf = stream.buf[n:]
+
+	return n, nil
+}
+
+// finish mark the transfer as done, and let queued transactions run
+func (stream *SDOStreamReader) finish() {
+	stream.done = true
+
+	if stream.queue != nil {
+		stream.queue.release()
+		stream.queue = nil
+	}
+}
+
+// Close implement io.Closer, and abort the transfer if not done
+func (stream *SDOStreamReader) Close() error {
+	if stream.closed {
+		return nil
+	}
+	stream.closed = true
+
+	if stream.done {
+		return nil
+	}
+	defer stream.finish()
+
+	stream.reader.releaseBlock()
+
+	return stream.reader.SDOClient.Abort(stream.reader.Index, stream.reader.SubIndex, SDOAbortGeneral)
+}
+
+// SDOStreamWriter download an object on demand, segment by segment or block by block
+type SDOStreamWriter struct {
+	writer *SDOWriter
+	block  bool
+	size   uint32
+	sent   uint32
+	buf    []byte
+	done   bool
+	closed bool
+	queue  *sdoTransactionQueue
+	client *SDOClient
+}
+
+// OpenWriter initiate the download of size bytes to index / subIndex, and returns an io.WriteCloser.
+// Closing the writer before size bytes were written abort the transfer.
+// Others transactions of sdoClient are queued until the transfer is done or aborted.
+func (sdoClient *SDOClient) OpenWriter(index uint16, subIndex uint8, size uint32, opts ...SDOOption) (io.WriteCloser, error) {
+	return sdoClient.OpenWriterContext(context.Background(), index, subIndex, size, opts...)
+}
+
+// OpenWriterContext is OpenWriter, waiting for others transactions and transferring until ctx is done.
+// If ctx is done during the transfer, the transfer is aborted.
+func (sdoClient *SDOClient) OpenWriterContext(ctx context.Context, index uint16, subIndex uint8, size uint32, opts ...SDOOption) (io.WriteCloser, error) {
+	if err := sdoClient.queue.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	options := NewSDOTransferOptions(opts...)
+	stream := &SDOStreamWriter{
+		writer: NewSDOWriter(sdoClient, index, subIndex, true),
+		size:   size,
+		queue:  &sdoClient.queue,
+		client: sdoClient,
+	}
+	stream.writer.SetContext(ctx)
+	stream.writer.SetOptions(options.ClientOptions(sdoClient.Options))
+
+	if options.Block {
+		err := stream.writer.StartBlockDownload(size, options.CRC)
+		if err == nil {
+			stream.block = true
+			return stream, nil
+		}
+
+		if !errors.Is(err, ErrSDOBlockNotSupported) {
+			sdoClient.queue.release()
+			return nil, sdoClient.abortOnContextError(index, subIndex, err)
+		}
+
+		// Fallback to segmented transfer
+		stream.writer = NewSDOWriter(sdoClient, index, subIndex, true)
+		stream.writer.SetContext(ctx)
+		stream.writer.SetOptions(options.ClientOptions(sdoClient.Options))
+	}
+
+	if err := stream.writer.RequestSegmentedDownload(size); err != nil {
+		sdoClient.queue.release()
+		return nil, sdoClient.abortOnContextError(index, subIndex, err)
+	}
+
+	return stream, nil
+}
+
+// Write implement io.Writer. Data is sent as soon as a segment is complete.
+func (stream *SDOStreamWriter) Write(p []byte) (int, error) {
+	if stream.closed {
+		return 0, ErrSDOStreamClosed
+	}
+
+	if stream.sent+uint32(len(stream.buf)+len(p)) > stream.size {
+		return 0, fmt.Errorf("write exceed size of %d bytes", stream.size)
+	}
+
+	stream.buf = append(stream.buf, p...)
+
+	if err := stream.flush(); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// flush complete segments, and the last segment
+func (stream *SDOStreamWriter) flush() error {
+	for !stream.done {
+		length := min(len(stream.buf), 7)
+		last := stream.sent+uint32(length) == stream.size
+
+		if length < 7 && !last {
+			return nil
+		}
+
+		segment := stream.buf[:length]
+
+		var err error
+		if stream.block {
+			err = stream.writer.WriteBlockSegment(segment, last)
+		} else {
+			err = stream.writer.WriteSegment(segment)
+		}
+
+		if err != nil {
+			stream.finish()
+			return stream.client.abortOnContextError(stream.writer.Index, stream.writer.SubIndex, err)
+		}
+
+		stream.buf = stream.buf[length:]
+		stream.sent += uint32(length)
+
+		if last {
+			defer stream.finish()
+
+			if stream.block {
+				return stream.writer.EndBlockDownload()
+			}
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// finish mark the transfer as done, and let queued transactions run
+func (stream *SDOStreamWriter) finish() {
+	stream.done = true
+
+	if stream.queue != nil {
+		stream.queue.release()
+		stream.queue = nil
+	}
+}
+
+// Close implement io.Closer, and abort the transfer if size bytes were not written
+func (stream *SDOStreamWriter) Close() error {
+	if stream.closed {
+		return nil
+	}
+	stream.closed = true
+
+	// Send last segment, if all data was written
+	if err := stream.flush(); err != nil {
+		return err
+	}
+
+	if stream.done {
+		return nil
+	}
+	defer stream.finish()
+
+	stream.writer.block = nil
+	if err := stream.writer.SDOClient.Abort(stream.writer.Index, stream.writer.SubIndex, SDOAbortGeneral); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("sdo transfer aborted after %d of %d bytes", stream.sent, stream.size)
+}
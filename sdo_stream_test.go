@@ -0,0 +1,212 @@
+package canopen
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSDOClient_OpenReader(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+
+	firmware := bytes.Repeat([]byte("firmware"), 300)
+	localNode.ObjectDic.FindIndex(0x2001).SetData(firmware)
+
+	for _, opts := range [][]SDOOption{{}, {WithBlockTransfer(32)}} {
+		reader, err := node.SDOClient.OpenReader(0x2001, 0, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, uint32(len(firmware)), reader.(*SDOStreamReader).Size())
+
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, firmware, data)
+		assert.NoError(t, reader.Close())
+	}
+
+	// Expedited
+	reader, err := node.SDOClient.OpenReader(0x1000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x92, 0x01, 0x02, 0x00}, data)
+}
+
+func TestSDOClient_OpenReaderCancel(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+
+	firmware := bytes.Repeat([]byte("firmware"), 300)
+	localNode.ObjectDic.FindIndex(0x2001).SetData(firmware)
+
+	for _, opts := range [][]SDOOption{{}, {WithBlockTransfer(32)}} {
+		reader, err := node.SDOClient.OpenReader(0x2001, 0, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buf := make([]byte, 10)
+		_, err = io.ReadFull(reader, buf)
+		assert.NoError(t, err)
+		assert.Equal(t, firmware[:10], buf)
+		assert.NoError(t, reader.Close())
+
+		_, err = reader.Read(buf)
+		assert.ErrorIs(t, err, ErrSDOStreamClosed)
+
+		// Server must be ready for a new transfer
+		data, err := node.SDOClient.Read(0x2000, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0x15, 0x00}, data)
+	}
+}
+
+func TestSDOClient_OpenReaderTimeout(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+
+	firmware := bytes.Repeat([]byte("firmware"), 300)
+	localNode.ObjectDic.FindIndex(0x2001).SetData(firmware)
+
+	reader, err := node.SDOClient.OpenReader(0x2001, 0, WithTimeout(10*time.Millisecond), WithRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == 0x605 && frm.Data[0] == SDOAbort
+	}
+	abortChan := node.Network.AcquireFramesChan(&filterFunc)
+	defer node.Network.ReleaseFramesChan(abortChan.ID)
+
+	// The server stop answering during the transfer
+	assert.NoError(t, localNode.SDOServer.Unlisten())
+
+	_, err = reader.Read(make([]byte, 10))
+	assert.ErrorIs(t, err, ErrSDOTimeout)
+
+	abort := waitFrame(t, abortChan, 100*time.Millisecond)
+	assert.Equal(t, SDOAbortTimeout, binary.LittleEndian.Uint32(abort.Data[4:]))
+}
+
+func TestSDOClient_OpenWriter(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+
+	firmware := bytes.Repeat([]byte("firmware"), 300)
+
+	for _, opts := range [][]SDOOption{{}, {WithBlockTransfer(0)}} {
+		writer, err := node.SDOClient.OpenWriter(0x2001, 0, uint32(len(firmware)), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n, err := io.CopyBuffer(writer, bytes.NewReader(firmware), make([]byte, 13))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(firmware)), n)
+		assert.NoError(t, writer.Close())
+		assert.Equal(t, firmware, localNode.ObjectDic.FindIndex(0x2001).GetData())
+
+		localNode.ObjectDic.FindIndex(0x2001).SetData(nil)
+	}
+
+	// Empty object
+	writer, err := node.SDOClient.OpenWriter(0x2001, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, writer.Close())
+	assert.Equal(t, []byte{}, localNode.ObjectDic.FindIndex(0x2001).GetData())
+}
+
+func TestSDOClient_OpenWriterCancel(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+	localNode.ObjectDic.FindIndex(0x2001).SetData([]byte("old"))
+
+	for _, opts := range [][]SDOOption{{}, {WithBlockTransfer(0)}} {
+		writer, err := node.SDOClient.OpenWriter(0x2001, 0, 100, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = writer.Write(bytes.Repeat([]byte{0x01}, 50))
+		assert.NoError(t, err)
+
+		_, err = writer.Write(bytes.Repeat([]byte{0x01}, 51))
+		assert.Error(t, err)
+
+		assert.Error(t, writer.Close())
+		assert.Equal(t, []byte("old"), localNode.ObjectDic.FindIndex(0x2001).GetData())
+	}
+}
+
+func TestSDOClient_OpenReaderQueue(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+
+	firmware := bytes.Repeat([]byte("firmware"), 300)
+	localNode.ObjectDic.FindIndex(0x2001).SetData(firmware)
+
+	reader, err := node.SDOClient.OpenReader(0x2001, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Transactions are queued until the end of the stream
+	done := make(chan []byte)
+	go func() {
+		data, err := node.SDOClient.Read(0x2000, 0)
+		assert.NoError(t, err)
+		done <- data
+	}()
+
+	buf := make([]byte, 100)
+	_, err = io.ReadFull(reader, buf)
+	assert.NoError(t, err)
+
+	select {
+	case <-done:
+		t.Fatal("SDOClient.Read() run during stream transfer")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, []byte{0x15, 0x00}, <-done)
+}
+
+func TestSDOClient_OpenContextQueued(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+	localNode.ObjectDic.FindIndex(0x2001).SetData(bytes.Repeat([]byte("firmware"), 300))
+
+	reader, err := node.SDOClient.OpenReader(0x2001, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Streams opened while another transfer runs give up when ctx is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = node.SDOClient.OpenReaderContext(ctx, 0x2001, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = node.SDOClient.OpenWriterContext(ctx, 0x2001, 0, 10)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.NoError(t, reader.Close())
+
+	writer, err := node.SDOClient.OpenWriterContext(context.Background(), 0x2001, 0, 3)
+	if assert.NoError(t, err) {
+		_, err = writer.Write([]byte("new"))
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+		assert.Equal(t, []byte("new"), localNode.ObjectDic.FindIndex(0x2001).GetData())
+	}
+}
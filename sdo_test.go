@@ -1,11 +1,14 @@
 package canopen
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jaster-prj/go-can"
+	"github.com/jaster-prj/go-canopen/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -256,3 +259,114 @@ func TestSDOClient_Send(t *testing.T) {
 		})
 	}
 }
+
+func getNodeWithAbortResponse() INode {
+	node := &nodeMock{
+		id:      0,
+		network: networkMock{},
+	}
+
+	frame1 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0x80, 0xE8, 0x03, 0x02, 0x00, 0x00, 0x02, 0x06}}
+	node.On("Send", uint32(0x600), []byte{0x40, 0xE8, 0x03, 0x02, 0x00, 0x00, 0x00, 0x00}).Return(nil, []send_response{{wait: time.Millisecond, frame: frame1}})
+	return node
+}
+
+func TestSDOClient_ReadAbort(t *testing.T) {
+	sdoClient := NewSDOClient(getNodeWithAbortResponse())
+
+	_, err := sdoClient.Read(0x3E8, 0x02)
+
+	var abortErr *SDOAbortError
+	if !errors.As(err, &abortErr) {
+		t.Fatalf("SDOClient.Read() error = %v, want SDOAbortError", err)
+	}
+	assert.Equal(t, &SDOAbortError{Index: 0x3E8, SubIndex: 0x02, Code: SDOAbortObjectDoesNotExist}, abortErr)
+	assert.Equal(t, "Object does not exist in the object dictionary", abortErr.Description())
+}
+
+func TestSDOClient_ReadContext(t *testing.T) {
+	node := &nodeMock{
+		id:      0,
+		network: networkMock{},
+	}
+	node.On("Send", uint32(0x600), []byte{0x40, 0xE8, 0x03, 0x02, 0x00, 0x00, 0x00, 0x00}).Return(nil, []send_response{})
+	node.On("Send", uint32(0x600), []byte{0x80, 0xE8, 0x03, 0x02, 0x00, 0x00, 0x04, 0x05}).Return(nil, []send_response{})
+
+	sdoClient := NewSDOClient(node)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := sdoClient.ReadContext(ctx, 0x3E8, 0x02)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), sdoDefaultTimeout)
+	node.AssertExpectations(t)
+}
+
+func getNodeWithBlockUpload() INode {
+	node := &nodeMock{
+		id:      0,
+		network: networkMock{},
+	}
+
+	data := []byte("SizeLongerAsOneLine")
+	crc := utils.CRC16CCITT(data)
+	initFrame := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0xC6, 0xE8, 0x03, 0x02, 0x13, 0x00, 0x00, 0x00}}
+	segFrame1 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0x01, 0x53, 0x69, 0x7A, 0x65, 0x4C, 0x6F, 0x6E}}
+	segFrame2 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0x02, 0x67, 0x65, 0x72, 0x41, 0x73, 0x4F, 0x6E}}
+	segFrame3 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0x83, 0x65, 0x4C, 0x69, 0x6E, 0x65, 0x00, 0x00}}
+	endFrame := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0xC9, byte(crc), byte(crc >> 8), 0x00, 0x00, 0x00, 0x00, 0x00}}
+	node.On("Send", uint32(0x600), []byte{0xA4, 0xE8, 0x03, 0x02, 0x7F, 0x00, 0x00, 0x00}).Return(nil, []send_response{{wait: time.Millisecond, frame: initFrame}})
+	node.On("Send", uint32(0x600), []byte{0xA3, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}).Return(nil, []send_response{
+		{wait: time.Millisecond, frame: segFrame1},
+		{wait: time.Millisecond, frame: segFrame2},
+		{wait: time.Millisecond, frame: segFrame3},
+	})
+	node.On("Send", uint32(0x600), []byte{0xA2, 0x03, 0x7F, 0x00, 0x00, 0x00, 0x00, 0x00}).Return(nil, []send_response{{wait: time.Millisecond, frame: endFrame}})
+	node.On("Send", uint32(0x600), []byte{0xA1, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}).Return(nil, []send_response{})
+	return node
+}
+
+func getNodeWithBlockUploadNotSupported() INode {
+	node := &nodeMock{
+		id:      0,
+		network: networkMock{},
+	}
+
+	abortFrame := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0x80, 0xE8, 0x03, 0x02, 0x01, 0x00, 0x04, 0x05}}
+	uploadFrame := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0x4F, 0xE8, 0x03, 0x02, 0x2A, 0x00, 0x00, 0x00}}
+	node.On("Send", uint32(0x600), []byte{0xA4, 0xE8, 0x03, 0x02, 0x7F, 0x00, 0x00, 0x00}).Return(nil, []send_response{{wait: time.Millisecond, frame: abortFrame}})
+	node.On("Send", uint32(0x600), []byte{0x40, 0xE8, 0x03, 0x02, 0x00, 0x00, 0x00, 0x00}).Return(nil, []send_response{{wait: time.Millisecond, frame: uploadFrame}})
+	return node
+}
+
+func TestSDOClient_ReadBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		getNode func() INode
+		want    []byte
+	}{
+		{
+			name:    "SDO block upload",
+			getNode: getNodeWithBlockUpload,
+			want:    []byte("SizeLongerAsOneLine"),
+		},
+		{
+			name:    "SDO block upload fallback to expedited",
+			getNode: getNodeWithBlockUploadNotSupported,
+			want:    []byte{0x2A},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sdoClient := NewSDOClient(tt.getNode())
+			got, err := sdoClient.Read(0x3E8, 0x02, WithBlockTransfer(0))
+			if err != nil {
+				t.Fatalf("SDOClient.Read() error = %v", err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,550 @@
+package canopen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+// ErrSDODataType is returned when the data type of a variable does not match the requested type
+var ErrSDODataType = errors.New("sdo data type mismatch")
+
+// ErrSDODataLength is returned when the length of received data does not match the requested type
+var ErrSDODataLength = errors.New("sdo data length mismatch")
+
+// sdoDataTypeSize returns the size in bytes of dataType, 0 for variable length types
+func sdoDataTypeSize(dataType byte) int {
+	switch dataType {
+	case Boolean, Integer8, Unsigned8:
+		return 1
+	case Integer16, Unsigned16:
+		return 2
+	case Integer32, Unsigned32, Real32:
+		return 4
+	case TimeOfDay, TimeDifference:
+		return 6
+	case Integer64, Unsigned64, Real64:
+		return 8
+	}
+
+	return 0
+}
+
+// readType read index / subIndex, and check data length against dataType
+func (sdoClient *SDOClient) readType(index uint16, subIndex uint8, dataType byte, opts ...SDOOption) ([]byte, error) {
+	return sdoClient.readTypeContext(context.Background(), index, subIndex, dataType, opts...)
+}
+
+// readTypeContext read index / subIndex until ctx is done, and check data length against dataType
+func (sdoClient *SDOClient) readTypeContext(ctx context.Context, index uint16, subIndex uint8, dataType byte, opts ...SDOOption) ([]byte, error) {
+	data, err := sdoClient.ReadContext(ctx, index, subIndex, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if size := sdoDataTypeSize(dataType); size != 0 && len(data) != size {
+		return nil, fmt.Errorf("%w: 0x%04X/%d has %d bytes, want %d", ErrSDODataLength, index, subIndex, len(data), size)
+	}
+
+	return data, nil
+}
+
+// FindVariable find a variable of the object dictionary by name, and check its data type
+// is one of dataTypes, if any. Members of records and arrays are named "Object.Member".
+func (sdoClient *SDOClient) FindVariable(name string, dataTypes ...byte) (*DicVariable, error) {
+	if sdoClient == nil {
+		return nil, errors.New("SDOClient required")
+	}
+
+	object := sdoClient.FindName(name)
+
+	if object == nil {
+		if parentName, memberName, ok := strings.Cut(name, "."); ok {
+			if parent := sdoClient.FindName(parentName); parent != nil {
+				object = parent.FindName(memberName)
+			}
+		}
+	}
+
+	if object == nil {
+		return nil, fmt.Errorf("object %s not found", name)
+	}
+
+	variable, ok := object.(*DicVariable)
+	if !ok {
+		return nil, fmt.Errorf("object %s is not a variable", name)
+	}
+
+	if len(dataTypes) == 0 {
+		return variable, nil
+	}
+
+	for _, dataType := range dataTypes {
+		if variable.DataType == dataType {
+			return variable, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s has data type 0x%02X", ErrSDODataType, name, variable.DataType)
+}
+
+// ReadBool read a BOOLEAN value
+func (sdoClient *SDOClient) ReadBool(index uint16, subIndex uint8, opts ...SDOOption) (bool, error) {
+	data, err := sdoClient.readType(index, subIndex, Boolean, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	return data[0] != 0, nil
+}
+
+// ReadBoolByName read a BOOLEAN variable found by name
+func (sdoClient *SDOClient) ReadBoolByName(name string, opts ...SDOOption) (bool, error) {
+	variable, err := sdoClient.FindVariable(name, Boolean)
+	if err != nil {
+		return false, err
+	}
+
+	return sdoClient.ReadBool(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteBool write a BOOLEAN value
+func (sdoClient *SDOClient) WriteBool(index uint16, subIndex uint8, value bool, opts ...SDOOption) error {
+	data := []byte{0x00}
+	if value {
+		data[0] = 0x01
+	}
+
+	return sdoClient.Write(index, subIndex, false, data, opts...)
+}
+
+// WriteBoolByName write a BOOLEAN variable found by name
+func (sdoClient *SDOClient) WriteBoolByName(name string, value bool, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Boolean)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteBool(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadU8 read an UNSIGNED8 value
+func (sdoClient *SDOClient) ReadU8(index uint16, subIndex uint8, opts ...SDOOption) (uint8, error) {
+	data, err := sdoClient.readType(index, subIndex, Unsigned8, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return data[0], nil
+}
+
+// ReadU8ByName read an UNSIGNED8 variable found by name
+func (sdoClient *SDOClient) ReadU8ByName(name string, opts ...SDOOption) (uint8, error) {
+	variable, err := sdoClient.FindVariable(name, Unsigned8)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadU8(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteU8 write an UNSIGNED8 value
+func (sdoClient *SDOClient) WriteU8(index uint16, subIndex uint8, value uint8, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, []byte{value}, opts...)
+}
+
+// WriteU8ByName write an UNSIGNED8 variable found by name
+func (sdoClient *SDOClient) WriteU8ByName(name string, value uint8, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Unsigned8)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteU8(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadU16 read an UNSIGNED16 value
+func (sdoClient *SDOClient) ReadU16(index uint16, subIndex uint8, opts ...SDOOption) (uint16, error) {
+	data, err := sdoClient.readType(index, subIndex, Unsigned16, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint16(data), nil
+}
+
+// ReadU16ByName read an UNSIGNED16 variable found by name
+func (sdoClient *SDOClient) ReadU16ByName(name string, opts ...SDOOption) (uint16, error) {
+	variable, err := sdoClient.FindVariable(name, Unsigned16)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadU16(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteU16 write an UNSIGNED16 value
+func (sdoClient *SDOClient) WriteU16(index uint16, subIndex uint8, value uint16, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, binary.LittleEndian.AppendUint16(nil, value), opts...)
+}
+
+// WriteU16ByName write an UNSIGNED16 variable found by name
+func (sdoClient *SDOClient) WriteU16ByName(name string, value uint16, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Unsigned16)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteU16(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadU32 read an UNSIGNED32 value
+func (sdoClient *SDOClient) ReadU32(index uint16, subIndex uint8, opts ...SDOOption) (uint32, error) {
+	return sdoClient.ReadU32Context(context.Background(), index, subIndex, opts...)
+}
+
+// ReadU32Context read an UNSIGNED32 value until ctx is done
+func (sdoClient *SDOClient) ReadU32Context(ctx context.Context, index uint16, subIndex uint8, opts ...SDOOption) (uint32, error) {
+	data, err := sdoClient.readTypeContext(ctx, index, subIndex, Unsigned32, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(data), nil
+}
+
+// ReadU32ByName read an UNSIGNED32 variable found by name
+func (sdoClient *SDOClient) ReadU32ByName(name string, opts ...SDOOption) (uint32, error) {
+	variable, err := sdoClient.FindVariable(name, Unsigned32)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadU32(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteU32 write an UNSIGNED32 value
+func (sdoClient *SDOClient) WriteU32(index uint16, subIndex uint8, value uint32, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, binary.LittleEndian.AppendUint32(nil, value), opts...)
+}
+
+// WriteU32ByName write an UNSIGNED32 variable found by name
+func (sdoClient *SDOClient) WriteU32ByName(name string, value uint32, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Unsigned32)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteU32(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadU64 read an UNSIGNED64 value
+func (sdoClient *SDOClient) ReadU64(index uint16, subIndex uint8, opts ...SDOOption) (uint64, error) {
+	data, err := sdoClient.readType(index, subIndex, Unsigned64, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint64(data), nil
+}
+
+// ReadU64ByName read an UNSIGNED64 variable found by name
+func (sdoClient *SDOClient) ReadU64ByName(name string, opts ...SDOOption) (uint64, error) {
+	variable, err := sdoClient.FindVariable(name, Unsigned64)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadU64(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteU64 write an UNSIGNED64 value
+func (sdoClient *SDOClient) WriteU64(index uint16, subIndex uint8, value uint64, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, binary.LittleEndian.AppendUint64(nil, value), opts...)
+}
+
+// WriteU64ByName write an UNSIGNED64 variable found by name
+func (sdoClient *SDOClient) WriteU64ByName(name string, value uint64, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Unsigned64)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteU64(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadI8 read an INTEGER8 value
+func (sdoClient *SDOClient) ReadI8(index uint16, subIndex uint8, opts ...SDOOption) (int8, error) {
+	data, err := sdoClient.readType(index, subIndex, Integer8, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int8(data[0]), nil
+}
+
+// ReadI8ByName read an INTEGER8 variable found by name
+func (sdoClient *SDOClient) ReadI8ByName(name string, opts ...SDOOption) (int8, error) {
+	variable, err := sdoClient.FindVariable(name, Integer8)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadI8(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteI8 write an INTEGER8 value
+func (sdoClient *SDOClient) WriteI8(index uint16, subIndex uint8, value int8, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, []byte{byte(value)}, opts...)
+}
+
+// WriteI8ByName write an INTEGER8 variable found by name
+func (sdoClient *SDOClient) WriteI8ByName(name string, value int8, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Integer8)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteI8(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadI16 read an INTEGER16 value
+func (sdoClient *SDOClient) ReadI16(index uint16, subIndex uint8, opts ...SDOOption) (int16, error) {
+	data, err := sdoClient.readType(index, subIndex, Integer16, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int16(binary.LittleEndian.Uint16(data)), nil
+}
+
+// ReadI16ByName read an INTEGER16 variable found by name
+func (sdoClient *SDOClient) ReadI16ByName(name string, opts ...SDOOption) (int16, error) {
+	variable, err := sdoClient.FindVariable(name, Integer16)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadI16(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteI16 write an INTEGER16 value
+func (sdoClient *SDOClient) WriteI16(index uint16, subIndex uint8, value int16, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, binary.LittleEndian.AppendUint16(nil, uint16(value)), opts...)
+}
+
+// WriteI16ByName write an INTEGER16 variable found by name
+func (sdoClient *SDOClient) WriteI16ByName(name string, value int16, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Integer16)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteI16(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadI32 read an INTEGER32 value
+func (sdoClient *SDOClient) ReadI32(index uint16, subIndex uint8, opts ...SDOOption) (int32, error) {
+	data, err := sdoClient.readType(index, subIndex, Integer32, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(binary.LittleEndian.Uint32(data)), nil
+}
+
+// ReadI32ByName read an INTEGER32 variable found by name
+func (sdoClient *SDOClient) ReadI32ByName(name string, opts ...SDOOption) (int32, error) {
+	variable, err := sdoClient.FindVariable(name, Integer32)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadI32(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteI32 write an INTEGER32 value
+func (sdoClient *SDOClient) WriteI32(index uint16, subIndex uint8, value int32, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, binary.LittleEndian.AppendUint32(nil, uint32(value)), opts...)
+}
+
+// WriteI32ByName write an INTEGER32 variable found by name
+func (sdoClient *SDOClient) WriteI32ByName(name string, value int32, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Integer32)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteI32(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadI64 read an INTEGER64 value
+func (sdoClient *SDOClient) ReadI64(index uint16, subIndex uint8, opts ...SDOOption) (int64, error) {
+	data, err := sdoClient.readType(index, subIndex, Integer64, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(binary.LittleEndian.Uint64(data)), nil
+}
+
+// ReadI64ByName read an INTEGER64 variable found by name
+func (sdoClient *SDOClient) ReadI64ByName(name string, opts ...SDOOption) (int64, error) {
+	variable, err := sdoClient.FindVariable(name, Integer64)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadI64(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteI64 write an INTEGER64 value
+func (sdoClient *SDOClient) WriteI64(index uint16, subIndex uint8, value int64, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, binary.LittleEndian.AppendUint64(nil, uint64(value)), opts...)
+}
+
+// WriteI64ByName write an INTEGER64 variable found by name
+func (sdoClient *SDOClient) WriteI64ByName(name string, value int64, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Integer64)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteI64(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadF32 read a REAL32 value
+func (sdoClient *SDOClient) ReadF32(index uint16, subIndex uint8, opts ...SDOOption) (float32, error) {
+	data, err := sdoClient.readType(index, subIndex, Real32, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Float32frombits(binary.LittleEndian.Uint32(data)), nil
+}
+
+// ReadF32ByName read a REAL32 variable found by name
+func (sdoClient *SDOClient) ReadF32ByName(name string, opts ...SDOOption) (float32, error) {
+	variable, err := sdoClient.FindVariable(name, Real32)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadF32(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteF32 write a REAL32 value
+func (sdoClient *SDOClient) WriteF32(index uint16, subIndex uint8, value float32, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, binary.LittleEndian.AppendUint32(nil, math.Float32bits(value)), opts...)
+}
+
+// WriteF32ByName write a REAL32 variable found by name
+func (sdoClient *SDOClient) WriteF32ByName(name string, value float32, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Real32)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteF32(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadF64 read a REAL64 value
+func (sdoClient *SDOClient) ReadF64(index uint16, subIndex uint8, opts ...SDOOption) (float64, error) {
+	data, err := sdoClient.readType(index, subIndex, Real64, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Float64frombits(binary.LittleEndian.Uint64(data)), nil
+}
+
+// ReadF64ByName read a REAL64 variable found by name
+func (sdoClient *SDOClient) ReadF64ByName(name string, opts ...SDOOption) (float64, error) {
+	variable, err := sdoClient.FindVariable(name, Real64)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadF64(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteF64 write a REAL64 value
+func (sdoClient *SDOClient) WriteF64(index uint16, subIndex uint8, value float64, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, binary.LittleEndian.AppendUint64(nil, math.Float64bits(value)), opts...)
+}
+
+// WriteF64ByName write a REAL64 variable found by name
+func (sdoClient *SDOClient) WriteF64ByName(name string, value float64, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, Real64)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteF64(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadString read a string value, use ReadStringByName to decode an UNICODE_STRING variable
+func (sdoClient *SDOClient) ReadString(index uint16, subIndex uint8, opts ...SDOOption) (string, error) {
+	data, err := sdoClient.Read(index, subIndex, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\x00"), nil
+}
+
+// ReadStringByName read a string variable found by name
+func (sdoClient *SDOClient) ReadStringByName(name string, opts ...SDOOption) (string, error) {
+	variable, err := sdoClient.FindVariable(name, VisibleString, OctetString, UnicodeString)
+	if err != nil {
+		return "", err
+	}
+
+	if variable.DataType != UnicodeString {
+		return sdoClient.ReadString(variable.Index, variable.SubIndex, opts...)
+	}
+
+	data, err := sdoClient.Read(variable.Index, variable.SubIndex, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data)%2 != 0 {
+		return "", fmt.Errorf("%w: %s has %d bytes, want an even length", ErrSDODataLength, name, len(data))
+	}
+
+	src := make([]uint16, len(data)/2)
+	for i := range src {
+		src[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+
+	return strings.TrimRight(string(utf16.Decode(src)), "\x00"), nil
+}
+
+// WriteString write a string value
+func (sdoClient *SDOClient) WriteString(index uint16, subIndex uint8, value string, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, []byte(value), opts...)
+}
+
+// WriteStringByName write a string variable found by name, encoded as utf-16 for UNICODE_STRING
+func (sdoClient *SDOClient) WriteStringByName(name string, value string, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, VisibleString, OctetString, UnicodeString)
+	if err != nil {
+		return err
+	}
+
+	if variable.DataType != UnicodeString {
+		return sdoClient.WriteString(variable.Index, variable.SubIndex, value, opts...)
+	}
+
+	data := []byte{}
+	for _, r := range utf16.Encode([]rune(value)) {
+		data = binary.LittleEndian.AppendUint16(data, r)
+	}
+
+	return sdoClient.Write(variable.Index, variable.SubIndex, false, data, opts...)
+}
@@ -0,0 +1,125 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTypedRemoteNode(t *testing.T) *Node {
+	network := getLoopbackNetwork(t)
+
+	if _, err := network.AddLocalNode(NewLocalNode(5, nil, nil), getLocalObjectDic()); err != nil {
+		t.Fatal(err)
+	}
+
+	return network.AddNode(NewNode(5, nil, nil), getLocalObjectDic(), false)
+}
+
+func TestSDOClient_ReadWriteTyped(t *testing.T) {
+	node := getTypedRemoteNode(t)
+	sdoClient := node.SDOClient
+
+	u32, err := sdoClient.ReadU32(0x1000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0x00020192), u32)
+
+	assert.NoError(t, sdoClient.WriteU16(0x2000, 0, 0x1234))
+	u16, err := sdoClient.ReadU16(0x2000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0x1234), u16)
+
+	i32, err := sdoClient.ReadI32(0x2003, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(-5), i32)
+
+	assert.NoError(t, sdoClient.WriteI32(0x2003, 1, -100000))
+	i32, err = sdoClient.ReadI32(0x2003, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(-100000), i32)
+
+	f32, err := sdoClient.ReadF32(0x2004, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(1.5), f32)
+
+	str, err := sdoClient.ReadString(0x2005, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "pump", str)
+
+	assert.NoError(t, sdoClient.WriteString(0x2005, 0, "centrifugal pump"))
+	str, err = sdoClient.ReadString(0x2005, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "centrifugal pump", str)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = sdoClient.ReadU32Context(canceled, 0x1000, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Length does not match
+	_, err = sdoClient.ReadU8(0x1000, 0)
+	assert.ErrorIs(t, err, ErrSDODataLength)
+	_, err = sdoClient.ReadU64(0x1000, 0)
+	assert.ErrorIs(t, err, ErrSDODataLength)
+}
+
+func TestSDOClient_ReadWriteTypedByName(t *testing.T) {
+	node := getTypedRemoteNode(t)
+	sdoClient := node.SDOClient
+
+	u32, err := sdoClient.ReadU32ByName("Device type")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0x00020192), u32)
+
+	assert.NoError(t, sdoClient.WriteU16ByName("Setpoint", 42))
+	u16, err := sdoClient.ReadU16ByName("Setpoint")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(42), u16)
+
+	assert.NoError(t, sdoClient.WriteI32ByName("Limits.Max", 7))
+	i32, err := sdoClient.ReadI32ByName("Limits.Max")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(7), i32)
+
+	assert.NoError(t, sdoClient.WriteF32ByName("Temperature", -3.25))
+	f32, err := sdoClient.ReadF32ByName("Temperature")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(-3.25), f32)
+
+	str, err := sdoClient.ReadStringByName("Label")
+	assert.NoError(t, err)
+	assert.Equal(t, "pump", str)
+
+	// Data type does not match
+	_, err = sdoClient.ReadU8ByName("Device type")
+	assert.ErrorIs(t, err, ErrSDODataType)
+	err = sdoClient.WriteI16ByName("Setpoint", 1)
+	assert.ErrorIs(t, err, ErrSDODataType)
+	_, err = sdoClient.ReadStringByName("Setpoint")
+	assert.ErrorIs(t, err, ErrSDODataType)
+
+	// Not found, or not a variable
+	_, err = sdoClient.ReadU32ByName("Unknown")
+	assert.Error(t, err)
+	_, err = sdoClient.ReadU32ByName("Limits")
+	assert.Error(t, err)
+}
+
+func TestNode_ReadWriteTyped(t *testing.T) {
+	sdoClient := getTypedRemoteNode(t).SDOClient
+
+	assert.NoError(t, sdoClient.WriteU16ByName("Setpoint", 42))
+	u16, err := sdoClient.ReadU16(0x2000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(42), u16)
+
+	// A node without sdo client returns errors
+	sdoClient = NewNode(6, nil, nil).SDOClient
+
+	_, err = sdoClient.ReadU32(0x1000, 0)
+	assert.Error(t, err)
+	_, err = sdoClient.ReadU8ByName("Device type")
+	assert.Error(t, err)
+	assert.Error(t, sdoClient.WriteI32(0x2003, 1, 7))
+}
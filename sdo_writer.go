@@ -1,9 +1,13 @@
 package canopen
 
 import (
+	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
 
 	"github.com/jaster-prj/go-can"
+	"github.com/jaster-prj/go-canopen/utils"
 )
 
 type SDOWriter struct {
@@ -14,6 +18,25 @@ type SDOWriter struct {
 	Pos          int
 	Size         uint32
 	ForceSegment bool
+
+	// block is the state of the block download in progress
+	block *sdoWriterBlock
+
+	// ctx of the transfer, see SetContext
+	ctx context.Context
+
+	// options of the transfer, see SetOptions
+	options *SDOClientOptions
+}
+
+type sdoWriterBlock struct {
+	size     uint8
+	crc      bool
+	crcValue uint16
+	lastLen  int
+
+	// segments of the current block, not yet acknowledged
+	segments [][]byte
 }
 
 func NewSDOWriter(sdoClient ISDOClient, index uint16, subIndex uint8, forceSegment bool) *SDOWriter {
@@ -25,6 +48,38 @@ func NewSDOWriter(sdoClient ISDOClient, index uint16, subIndex uint8, forceSegme
 	}
 }
 
+// SetContext set the context used for the transfer
+func (writer *SDOWriter) SetContext(ctx context.Context) {
+	writer.ctx = ctx
+}
+
+// SetOptions set timeouts and retries used for the transfer, instead of the options of the client
+func (writer *SDOWriter) SetOptions(options SDOClientOptions) {
+	writer.options = &options
+}
+
+// clientOptions returns the options to use for an initiate request or a segment request
+func (writer *SDOWriter) clientOptions(initiate bool) SDOClientOptions {
+	if writer.options != nil {
+		return writer.options.forRequest(initiate)
+	}
+
+	return writer.SDOClient.GetOptions().forRequest(initiate)
+}
+
+// send request and wait for response, with the options of the transfer
+func (writer *SDOWriter) send(req []byte, expectFunc networkFramesChanFilterFunc, initiate bool) (*can.Frame, error) {
+	return writer.SDOClient.SendOptions(writer.context(), req, expectFunc, writer.clientOptions(initiate))
+}
+
+func (writer *SDOWriter) context() context.Context {
+	if writer.ctx == nil {
+		return context.Background()
+	}
+
+	return writer.ctx
+}
+
 // buildRequestDownloadBuf
 func (writer *SDOWriter) buildRequestDownloadBuf(data []byte, size *uint32) (string, []byte) {
 	buf := make([]byte, 8) // 8 len is important
@@ -80,6 +135,10 @@ func (writer *SDOWriter) RequestDownload(data []byte) error {
 
 func (writer *SDOWriter) writeBufferExpedited(cmd []byte) error {
 	expectFunc := func(frm *can.Frame) bool {
+		if isSDOAbortFrame(frm, writer.Index, writer.SubIndex) {
+			return true
+		}
+
 		resCommand := frm.Data[0]
 		resIndex := binary.LittleEndian.Uint16(frm.Data[1:])
 		resSubindex := frm.Data[3]
@@ -100,12 +159,40 @@ func (writer *SDOWriter) writeBufferExpedited(cmd []byte) error {
 		return true
 	}
 
-	_, err := writer.SDOClient.Send(cmd, &expectFunc, nil, nil)
+	_, err := writer.send(cmd, &expectFunc, true)
 	return err
 }
 
 func (writer *SDOWriter) writeBufferSegmented(cmd []byte, data []byte) error {
+	if err := writer.requestSegmentedDownload(cmd); err != nil {
+		return err
+	}
+
+	// At least one segment is sent, even without data
+	for segment := 0; segment == 0 || writer.Pos < int(writer.Size); segment++ {
+		frameSize := min(int(writer.Size)-writer.Pos, 7)
+		if err := writer.WriteSegment(data[writer.Pos : writer.Pos+frameSize]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequestSegmentedDownload initiate a segmented download of size bytes
+func (writer *SDOWriter) RequestSegmentedDownload(size uint32) error {
+	writer.ForceSegment = true
+	_, cmd := writer.buildRequestDownloadBuf(nil, &size)
+	writer.Size = size
+
+	return writer.requestSegmentedDownload(cmd)
+}
+
+func (writer *SDOWriter) requestSegmentedDownload(cmd []byte) error {
 	expectFunc := func(frm *can.Frame) bool {
+		if isSDOAbortFrame(frm, writer.Index, writer.SubIndex) {
+			return true
+		}
+
 		resCommand := frm.Data[0]
 		resIndex := binary.LittleEndian.Uint16(frm.Data[1:])
 		resSubindex := frm.Data[3]
@@ -126,49 +213,246 @@ func (writer *SDOWriter) writeBufferSegmented(cmd []byte, data []byte) error {
 		return true
 	}
 
-	_, err := writer.SDOClient.Send(cmd, &expectFunc, nil, nil)
+	_, err := writer.send(cmd, &expectFunc, true)
+	return err
+}
+
+// WriteSegment send a segment of at most 7 bytes. The last segment is
+// detected with the size given on download initiate.
+func (writer *SDOWriter) WriteSegment(data []byte) error {
+	buf := make([]byte, 8)
+	buf[0] = SDORequestSegmentDownload | writer.Toggle
+	frameSize := len(data)
+	if writer.Pos+frameSize >= int(writer.Size) {
+		buf[0] = buf[0] | SDONoMoreData
+	}
+	buf[0] = buf[0] | (7-uint8(frameSize))<<1
+	copy(buf[1:frameSize+1], data)
+
+	expectFunc := func(frm *can.Frame) bool {
+		if isSDOAbortFrame(frm, writer.Index, writer.SubIndex) {
+			return true
+		}
 
+		resCommand := frm.Data[0]
+		// Check response validity
+		if (resCommand & 0xE0) != SDOResponseSegmentDownload {
+			return false
+		}
+		if (resCommand & SDOToggleBit) != writer.Toggle {
+			return false
+		}
+		return true
+	}
+	_, err := writer.send(buf, &expectFunc, false)
 	if err != nil {
 		return err
 	}
+	writer.Toggle = writer.Toggle ^ SDOToggleBit
+	writer.Pos = writer.Pos + frameSize
+	return nil
+}
 
-	for {
-		buf := make([]byte, 8)
-		if writer.Pos >= int(writer.Size) {
-			break
+// Write data to sdo client
+func (writer *SDOWriter) Write(data []byte) error {
+	return writer.RequestDownload(data)
+}
+
+// buildRequestBlockDownloadBuf
+func (writer *SDOWriter) buildRequestBlockDownloadBuf(size uint32, crc bool) []byte {
+	buf := make([]byte, 8)
+
+	command := SDORequestBlockDownload | SDOBlockSizeSpecified | SDOBlockInitiate
+	if crc {
+		command |= SDOBlockCRC
+	}
+	buf[0] = command
+	binary.LittleEndian.PutUint16(buf[1:], writer.Index)
+	buf[3] = writer.SubIndex
+	binary.LittleEndian.PutUint32(buf[4:], size)
+
+	return buf
+}
+
+// RequestBlockDownload initiate a block download, and returns the block size
+// given by the server and true if server support crc
+func (writer *SDOWriter) RequestBlockDownload(size uint32, crc bool) (uint8, bool, error) {
+	expectFunc := func(frm *can.Frame) bool {
+		if isSDOAbortFrame(frm, writer.Index, writer.SubIndex) {
+			return true
 		}
-		buf[0] = SDORequestSegmentDownload | writer.Toggle
-		frameSize := min(int(writer.Size)-writer.Pos, 7)
-		if writer.Pos+frameSize >= int(writer.Size)-1 {
-			buf[0] = buf[0] | SDONoMoreData
-		}
-		buf[0] = buf[0] | (7-uint8(frameSize))<<1
-		copy(buf[1:frameSize+1], data[writer.Pos:writer.Pos+frameSize])
-
-		expectFunc := func(frm *can.Frame) bool {
-			resCommand := frm.Data[0]
-			// Check response validity
-			if (resCommand & 0xE0) != SDOResponseSegmentDownload {
-				return false
-			}
-			if (resCommand & SDOToggleBit) != writer.Toggle {
-				return false
-			}
+
+		resCommand := frm.Data[0]
+		resIndex := binary.LittleEndian.Uint16(frm.Data[1:])
+		resSubindex := frm.Data[3]
+
+		// Check response validity
+		if (resCommand & 0xE3) != (SDOResponseBlockDownload | SDOBlockInitiate) {
+			return false
+		}
+
+		return resIndex == writer.Index && resSubindex == writer.SubIndex
+	}
+
+	frm, err := writer.send(writer.buildRequestBlockDownloadBuf(size, crc), &expectFunc, true)
+	if err != nil {
+		var abortErr *SDOAbortError
+		if errors.As(err, &abortErr) && abortErr.Code == SDOAbortCommandSpecifier {
+			return 0, false, fmt.Errorf("%w: %w", ErrSDOBlockNotSupported, err)
+		}
+
+		return 0, false, err
+	}
+
+	blockSize := frm.Data[4]
+	if blockSize == 0 || blockSize > SDOBlockMaxSize {
+		writer.SDOClient.Abort(writer.Index, writer.SubIndex, SDOAbortBlockSize)
+		return 0, false, fmt.Errorf("invalid block size %d", blockSize)
+	}
+
+	return blockSize, (frm.Data[0] & SDOBlockCRC) != 0, nil
+}
+
+// StartBlockDownload initiate a block download of size bytes
+func (writer *SDOWriter) StartBlockDownload(size uint32, crc bool) error {
+	writer.Size = size
+	writer.Pos = 0
+
+	blockSize, serverCRC, err := writer.RequestBlockDownload(size, crc)
+	if err != nil {
+		return err
+	}
+
+	writer.block = &sdoWriterBlock{
+		size: blockSize,
+		crc:  crc && serverCRC,
+	}
+
+	return nil
+}
+
+// WriteBlockSegment send a segment of at most 7 bytes, last must be true for the last segment.
+// Segments not acknowledged by the server are sent again in the next block.
+func (writer *SDOWriter) WriteBlockSegment(data []byte, last bool) error {
+	if writer.block == nil {
+		return errors.New("no block download in progress")
+	}
+
+	writer.block.crcValue = utils.UpdateCRC16CCITT(writer.block.crcValue, data)
+	if last {
+		writer.block.lastLen = len(data)
+	}
+
+	return writer.writeBlockSegment(data, last)
+}
+
+func (writer *SDOWriter) writeBlockSegment(data []byte, last bool) error {
+	block := writer.block
+	block.segments = append(block.segments, data)
+	seq := uint8(len(block.segments))
+
+	buf := make([]byte, 8)
+	buf[0] = seq
+	if last {
+		buf[0] |= SDOBlockNoMoreData
+	}
+	copy(buf[1:], data)
+
+	// Only the last segment of a block is acknowledged by the server
+	if seq < block.size && !last {
+		return writer.SDOClient.SendRequest(buf)
+	}
+
+	expectFunc := func(frm *can.Frame) bool {
+		if isSDOAbortFrame(frm, writer.Index, writer.SubIndex) {
 			return true
 		}
-		_, err := writer.SDOClient.Send(buf, &expectFunc, nil, nil)
-		if err != nil {
+
+		return frm.Data[0] == (SDOResponseBlockDownload | SDOBlockAck)
+	}
+
+	// A segment must not be sent twice, so no retry here
+	options := writer.clientOptions(false)
+	options.Retries = 0
+	frm, err := writer.SDOClient.SendOptions(writer.context(), buf, &expectFunc, options)
+	if err != nil {
+		return err
+	}
+
+	ackSeq := int(frm.Data[1])
+	if ackSeq > len(block.segments) {
+		writer.SDOClient.Abort(writer.Index, writer.SubIndex, SDOAbortSequenceNumber)
+		return fmt.Errorf("invalid acknowledged sequence number %d", ackSeq)
+	}
+
+	for _, segment := range block.segments[:ackSeq] {
+		writer.Pos += len(segment)
+	}
+
+	remaining := block.segments[ackSeq:]
+	block.segments = nil
+
+	if frm.Data[2] != 0 && frm.Data[2] <= SDOBlockMaxSize {
+		block.size = frm.Data[2]
+	}
+
+	// Continue from the last acknowledged segment
+	for i, segment := range remaining {
+		if err := writer.writeBlockSegment(segment, last && i == len(remaining)-1); err != nil {
 			return err
 		}
-		writer.Toggle = writer.Toggle ^ SDOToggleBit
-		writer.Pos = writer.Pos + frameSize
 	}
+
 	return nil
 }
 
-// Write data to sdo client
-func (writer *SDOWriter) Write(data []byte) error {
-	return writer.RequestDownload(data)
+// EndBlockDownload end the block download, after the last segment
+func (writer *SDOWriter) EndBlockDownload() error {
+	block := writer.block
+	if block == nil {
+		return errors.New("no block download in progress")
+	}
+	writer.block = nil
+
+	buf := make([]byte, 8)
+	buf[0] = SDORequestBlockDownload | SDOBlockEnd
+	buf[0] |= uint8(7-block.lastLen) << 2
+	if block.crc {
+		binary.LittleEndian.PutUint16(buf[1:], block.crcValue)
+	}
+
+	expectFunc := func(frm *can.Frame) bool {
+		if isSDOAbortFrame(frm, writer.Index, writer.SubIndex) {
+			return true
+		}
+
+		return frm.Data[0] == (SDOResponseBlockDownload | SDOBlockEnd)
+	}
+
+	_, err := writer.send(buf, &expectFunc, false)
+	return err
+}
+
+// WriteBlock download data using block transfer
+func (writer *SDOWriter) WriteBlock(data []byte, crc bool) error {
+	if err := writer.StartBlockDownload(uint32(len(data)), crc); err != nil {
+		return err
+	}
+
+	// At least one segment is sent, even without data
+	nofSegments := max(1, (len(data)+6)/7)
+
+	for segment := 0; segment < nofSegments; segment++ {
+		start := segment * 7
+		end := min(start+7, len(data))
+
+		if err := writer.WriteBlockSegment(data[start:end], segment == nofSegments-1); err != nil {
+			writer.block = nil
+			return err
+		}
+	}
+
+	return writer.EndBlockDownload()
 }
 
 func min(a, b int) int {
@@ -1,12 +1,14 @@
 package canopen
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/jaster-prj/go-can"
+	"github.com/jaster-prj/go-canopen/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -83,6 +85,59 @@ func getSDOClientMockSegmentedFailed5() *sdoClientMock {
 	return client
 }
 
+func getSDOClientMockExpeditedAborted() *sdoClientMock {
+	client := &sdoClientMock{RXCobID: 0x600, TXCobID: 0x580}
+	frame1 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0x80, 0xE8, 0x03, 0x02, 0x02, 0x00, 0x01, 0x06}}
+	client.On("Send", []byte{0x23, 0xE8, 0x03, 0x02, 0x4C, 0x69, 0x6E, 0x65}).Return(&frame1, nil)
+	return client
+}
+
+func getSDOClientMockSegmentedAborted() *sdoClientMock {
+	client := &sdoClientMock{RXCobID: 0x600, TXCobID: 0x580}
+	frame1 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0x60, 0xE8, 0x03, 0x02, 0x00, 0x00, 0x00, 0x00}}
+	frame2 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0x80, 0xE8, 0x03, 0x02, 0x05, 0x00, 0x04, 0x05}}
+	client.On("Send", []byte{0x21, 0xE8, 0x03, 0x02, 0x13, 0x00, 0x00, 0x00}).Return(&frame1, nil)
+	client.On("Send", []byte{0x00, 0x53, 0x69, 0x7A, 0x65, 0x4C, 0x6F, 0x6E}).Return(&frame2, nil)
+	return client
+}
+
+func getSDOClientMockBlockSuccess() *sdoClientMock {
+	client := &sdoClientMock{RXCobID: 0x600, TXCobID: 0x580}
+	crc := utils.CRC16CCITT([]byte(bigData))
+	frame1 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0xA4, 0xE8, 0x03, 0x02, 0x02, 0x00, 0x00, 0x00}}
+	frame2 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0xA2, 0x02, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00}}
+	frame3 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0xA2, 0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00}}
+	frame4 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0xA1, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}}
+	client.On("Send", []byte{0xC6, 0xE8, 0x03, 0x02, 0x13, 0x00, 0x00, 0x00}).Return(&frame1, nil)
+	client.On("SendRequest", []byte{0x01, 0x53, 0x69, 0x7A, 0x65, 0x4C, 0x6F, 0x6E}).Return(nil)
+	client.On("Send", []byte{0x02, 0x67, 0x65, 0x72, 0x41, 0x73, 0x4F, 0x6E}).Return(&frame2, nil)
+	client.On("Send", []byte{0x81, 0x65, 0x4C, 0x69, 0x6E, 0x65, 0x00, 0x00}).Return(&frame3, nil)
+	client.On("Send", []byte{0xC9, byte(crc), byte(crc >> 8), 0x00, 0x00, 0x00, 0x00, 0x00}).Return(&frame4, nil)
+	return client
+}
+
+func getSDOClientMockBlockRetransmit() *sdoClientMock {
+	client := &sdoClientMock{RXCobID: 0x600, TXCobID: 0x580}
+	frame1 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0xA0, 0xE8, 0x03, 0x02, 0x02, 0x00, 0x00, 0x00}}
+	frame2 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0xA2, 0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00}}
+	frame3 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0xA2, 0x02, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00}}
+	frame4 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0xA1, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}}
+	client.On("Send", []byte{0xC2, 0xE8, 0x03, 0x02, 0x13, 0x00, 0x00, 0x00}).Return(&frame1, nil)
+	client.On("SendRequest", []byte{0x01, 0x53, 0x69, 0x7A, 0x65, 0x4C, 0x6F, 0x6E}).Return(nil)
+	client.On("Send", []byte{0x02, 0x67, 0x65, 0x72, 0x41, 0x73, 0x4F, 0x6E}).Return(&frame2, nil)
+	client.On("SendRequest", []byte{0x01, 0x67, 0x65, 0x72, 0x41, 0x73, 0x4F, 0x6E}).Return(nil)
+	client.On("Send", []byte{0x82, 0x65, 0x4C, 0x69, 0x6E, 0x65, 0x00, 0x00}).Return(&frame3, nil)
+	client.On("Send", []byte{0xC9, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}).Return(&frame4, nil)
+	return client
+}
+
+func getSDOClientMockBlockNotSupported() *sdoClientMock {
+	client := &sdoClientMock{RXCobID: 0x600, TXCobID: 0x580}
+	frame1 := can.Frame{ArbitrationID: 0x580, Data: [8]byte{0x80, 0xE8, 0x03, 0x02, 0x01, 0x00, 0x04, 0x05}}
+	client.On("Send", []byte{0xC6, 0xE8, 0x03, 0x02, 0x13, 0x00, 0x00, 0x00}).Return(&frame1, nil)
+	return client
+}
+
 type sdoClientMock struct {
 	mock.Mock
 	RXCobID uint32
@@ -104,13 +159,24 @@ func (s *sdoClientMock) FindName(name string) DicObject {
 	return args.Get(0).(DicObject)
 }
 
-func (s *sdoClientMock) Read(index uint16, subIndex uint8) ([]byte, error) {
+func (s *sdoClientMock) Read(index uint16, subIndex uint8, opts ...SDOOption) ([]byte, error) {
 	args := s.Called(index, subIndex)
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (s *sdoClientMock) ReadContext(ctx context.Context, index uint16, subIndex uint8, opts ...SDOOption) ([]byte, error) {
+	return s.Read(index, subIndex, opts...)
+}
+
 func (s *sdoClientMock) Send(req []byte, expectFunc networkFramesChanFilterFunc, timeout *time.Duration, retryCount *int) (*can.Frame, error) {
-	args := s.Called(req)
+	return s.SendContext(context.Background(), req, expectFunc, timeout, retryCount)
+}
+
+func (s *sdoClientMock) SendContext(ctx context.Context, req []byte, expectFunc networkFramesChanFilterFunc, timeout *time.Duration, retryCount *int) (*can.Frame, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	args := s.MethodCalled("Send", req)
 	argFrame := args.Get(0)
 	if argFrame == nil {
 		return nil, args.Error(1)
@@ -124,19 +190,38 @@ func (s *sdoClientMock) Send(req []byte, expectFunc networkFramesChanFilterFunc,
 			return nil, errors.New("Frame not matched")
 		}
 	}
+	if abortErr := newSDOAbortErrorFromFrame(returnFrame); abortErr != nil {
+		return nil, abortErr
+	}
 	return returnFrame, args.Error(1)
 }
 
+func (s *sdoClientMock) GetOptions() SDOClientOptions {
+	return DefaultSDOClientOptions()
+}
+
+func (s *sdoClientMock) SendOptions(ctx context.Context, req []byte, expectFunc networkFramesChanFilterFunc, options SDOClientOptions) (*can.Frame, error) {
+	return s.SendContext(ctx, req, expectFunc, nil, nil)
+}
+
+func (s *sdoClientMock) Abort(index uint16, subIndex uint8, code uint32) error {
+	return s.SendRequest(buildSDOAbortBuf(index, subIndex, code))
+}
+
 func (s *sdoClientMock) SendRequest(req []byte) error {
 	args := s.Called(req)
 	return args.Error(0)
 }
 
-func (s *sdoClientMock) Write(index uint16, subIndex uint8, forceSegment bool, data []byte) error {
+func (s *sdoClientMock) Write(index uint16, subIndex uint8, forceSegment bool, data []byte, opts ...SDOOption) error {
 	args := s.Called(index, subIndex, forceSegment, data)
 	return args.Error(0)
 }
 
+func (s *sdoClientMock) WriteContext(ctx context.Context, index uint16, subIndex uint8, forceSegment bool, data []byte, opts ...SDOOption) error {
+	return s.Write(index, subIndex, forceSegment, data, opts...)
+}
+
 func TestSDOWriter_buildRequestDownloadBuf(t *testing.T) {
 	type fields struct {
 		SDOClient    ISDOClient
@@ -241,10 +326,11 @@ func TestSDOWriter_RequestDownload(t *testing.T) {
 		data []byte
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
+		name      string
+		fields    fields
+		args      args
+		wantErr   bool
+		wantAbort uint32
 	}{
 		{
 			name: "Write expedited success",
@@ -350,13 +436,93 @@ func TestSDOWriter_RequestDownload(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Write expedited aborted",
+			fields: fields{
+				SDOClientFunc: getSDOClientMockExpeditedAborted,
+				Index:         0x3E8, //1000
+				SubIndex:      0x02,
+				ForceSegment:  false,
+			},
+			args: args{
+				data: []byte(smallData),
+			},
+			wantErr:   true,
+			wantAbort: SDOAbortReadOnly,
+		},
+		{
+			name: "Write segmented aborted",
+			fields: fields{
+				SDOClientFunc: getSDOClientMockSegmentedAborted,
+				Index:         0x3E8, //1000
+				SubIndex:      0x02,
+				ForceSegment:  false,
+			},
+			args: args{
+				data: []byte(bigData),
+			},
+			wantErr:   true,
+			wantAbort: SDOAbortOutOfMemory,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			writer := NewSDOWriter(tt.fields.SDOClientFunc(), tt.fields.Index, tt.fields.SubIndex, tt.fields.ForceSegment)
-			if err := writer.RequestDownload(tt.args.data); (err != nil) != tt.wantErr {
+			err := writer.RequestDownload(tt.args.data)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("SDOWriter.RequestDownload() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if tt.wantAbort != 0 {
+				var abortErr *SDOAbortError
+				if !errors.As(err, &abortErr) {
+					t.Fatalf("SDOWriter.RequestDownload() error = %v, want SDOAbortError", err)
+				}
+				assert.Equal(t, tt.wantAbort, abortErr.Code)
+			}
+		})
+	}
+}
+
+func TestSDOWriter_WriteBlock(t *testing.T) {
+	tests := []struct {
+		name          string
+		SDOClientFunc func() *sdoClientMock
+		crc           bool
+		wantErr       error
+	}{
+		{
+			name:          "Write block success",
+			SDOClientFunc: getSDOClientMockBlockSuccess,
+			crc:           true,
+			wantErr:       nil,
+		},
+		{
+			name:          "Write block with retransmission",
+			SDOClientFunc: getSDOClientMockBlockRetransmit,
+			crc:           false,
+			wantErr:       nil,
+		},
+		{
+			name:          "Write block not supported",
+			SDOClientFunc: getSDOClientMockBlockNotSupported,
+			crc:           true,
+			wantErr:       ErrSDOBlockNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tt.SDOClientFunc()
+			writer := NewSDOWriter(client, 0x3E8, 0x02, false)
+			err := writer.WriteBlock([]byte(bigData), tt.crc)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, int(sizeBigData), writer.Pos)
+				client.AssertExpectations(t)
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SDOWriter.WriteBlock() error = %v, wantErr %v", err, tt.wantErr)
+			}
 		})
 	}
 }
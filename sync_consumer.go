@@ -0,0 +1,359 @@
+package canopen
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaster-prj/go-can"
+)
+
+// syncEventsChanSize is the buffer size of sync events chans
+const syncEventsChanSize = 16
+
+// SyncEvent is sent for each sync received
+type SyncEvent struct {
+	Timestamp time.Time
+	// Counter is the sync counter, -1 for a sync without counter
+	Counter int
+	// Period since the previous sync, 0 for the first one
+	Period time.Duration
+	// Jitter is the difference between Period and the communication cycle period
+	Jitter time.Duration
+	// Missed is the number of syncs missed before this one
+	Missed int
+	// WindowViolations is the number of pdos not sent during the synchronous window of the previous sync
+	WindowViolations int
+}
+
+type SyncEventsChan struct {
+	chanID string
+	C      chan SyncEvent
+}
+
+// SyncConsumerStats are the statistics of the syncs received
+type SyncConsumerStats struct {
+	Count         uint64
+	LastTimestamp time.Time
+	// LastCounter is the counter of the last sync, -1 without counter
+	LastCounter int
+
+	LastPeriod time.Duration
+	MinPeriod  time.Duration
+	MaxPeriod  time.Duration
+	MeanPeriod time.Duration
+	// MaxJitter is the maximum difference between an actual period and Period
+	MaxJitter time.Duration
+
+	// Missed syncs, detected when the actual period is more than 1.5 Period
+	Missed uint64
+	// WindowViolations is the number of pdos not sent during the synchronous window
+	WindowViolations uint64
+
+	periodsSum time.Duration
+}
+
+// syncPDOMap is a pdo map transmitted on sync
+type syncPDOMap struct {
+	pdoMap *PDOMap
+	syncs  int
+}
+
+// SyncConsumer receive sync messages, monitor their period, and transmit
+// pdo maps with a synchronous transmission type
+type SyncConsumer struct {
+	sync.Mutex
+
+	Network *Network
+	// CobID of sync messages, 0x80 by default
+	CobID uint32
+	// Period is the expected communication cycle period 0x1006, 0 to not monitor
+	Period time.Duration
+	// WindowLength is the synchronous window length 0x1007, 0 for no window
+	WindowLength time.Duration
+
+	stats       SyncConsumerStats
+	pdoMaps     []*syncPDOMap
+	eventsChans []*SyncEventsChan
+
+	listening    bool
+	stopChan     chan bool
+	framesChanID string
+}
+
+// NewSyncConsumer return a new SyncConsumer on the default COB-ID 0x80
+func NewSyncConsumer(network *Network) *SyncConsumer {
+	return &SyncConsumer{
+		Network: network,
+		CobID:   0x80,
+		stats:   SyncConsumerStats{LastCounter: -1},
+	}
+}
+
+// Configure the consumer with the sync COB-ID 0x1005, the communication cycle period 0x1006
+// and the synchronous window length 0x1007 of objectDic
+func (consumer *SyncConsumer) Configure(objectDic *DicObjectDic) error {
+	object := objectDic.FindIndex(0x1005)
+	if object == nil || len(object.GetData()) != 4 {
+		return errors.New("sync COB-ID 0x1005 not found")
+	}
+	cobID := binary.LittleEndian.Uint32(object.GetData()) & 0x7FF
+
+	var period, windowLength time.Duration
+	if object := objectDic.FindIndex(0x1006); object != nil && len(object.GetData()) == 4 {
+		period = time.Duration(binary.LittleEndian.Uint32(object.GetData())) * time.Microsecond
+	}
+	if object := objectDic.FindIndex(0x1007); object != nil && len(object.GetData()) == 4 {
+		windowLength = time.Duration(binary.LittleEndian.Uint32(object.GetData())) * time.Microsecond
+	}
+
+	consumer.Lock()
+	listening := consumer.listening
+	consumer.Unlock()
+
+	consumer.Unlisten()
+
+	consumer.Lock()
+	consumer.CobID = cobID
+	consumer.Period = period
+	consumer.WindowLength = windowLength
+	consumer.Unlock()
+
+	if listening {
+		return consumer.Listen()
+	}
+
+	return nil
+}
+
+// Listen for sync messages on network, statistics are reset
+func (consumer *SyncConsumer) Listen() error {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	if consumer.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	if consumer.listening {
+		return nil
+	}
+
+	cobID := consumer.CobID
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == cobID
+	}
+
+	framesChan := consumer.Network.AcquireFramesChan(&filterFunc)
+	consumer.framesChanID = framesChan.ID
+	consumer.stopChan = make(chan bool)
+	consumer.stats = SyncConsumerStats{LastCounter: -1}
+	consumer.listening = true
+
+	go func(stopChan chan bool) {
+		for {
+			select {
+			case <-stopChan:
+				return
+			case frm, ok := <-framesChan.C:
+				if !ok {
+					return
+				}
+				consumer.handleSync(frm.GetData(), time.Now())
+			}
+		}
+	}(consumer.stopChan)
+
+	return nil
+}
+
+// Unlisten stop listening sync messages
+func (consumer *SyncConsumer) Unlisten() {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	if !consumer.listening {
+		return
+	}
+
+	close(consumer.stopChan)
+	consumer.Network.ReleaseFramesChan(consumer.framesChanID)
+	consumer.listening = false
+}
+
+// Stats returns the statistics of the syncs received since Listen
+func (consumer *SyncConsumer) Stats() SyncConsumerStats {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	return consumer.stats
+}
+
+// AddPDOMap transmit pdoMap every TransType syncs, for TransType 1 to 240,
+// or on next sync when its data changed for TransType 0
+func (consumer *SyncConsumer) AddPDOMap(pdoMap *PDOMap) error {
+	if pdoMap.TransType > 240 {
+		return errors.New("pdo map transmission type is not synchronous")
+	}
+
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	for _, syncMap := range consumer.pdoMaps {
+		if syncMap.pdoMap == pdoMap {
+			return nil
+		}
+	}
+
+	consumer.pdoMaps = append(consumer.pdoMaps, &syncPDOMap{pdoMap: pdoMap})
+
+	return nil
+}
+
+// RemovePDOMap stop transmitting pdoMap on sync
+func (consumer *SyncConsumer) RemovePDOMap(pdoMap *PDOMap) {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	for idx, syncMap := range consumer.pdoMaps {
+		if syncMap.pdoMap == pdoMap {
+			consumer.pdoMaps = append(consumer.pdoMaps[:idx], consumer.pdoMaps[idx+1:]...)
+			return
+		}
+	}
+}
+
+func (consumer *SyncConsumer) handleSync(data []byte, now time.Time) {
+	consumer.Lock()
+
+	stats := &consumer.stats
+	event := SyncEvent{Timestamp: now, Counter: -1}
+
+	if len(data) == 1 {
+		event.Counter = int(data[0])
+	}
+
+	if stats.Count > 0 {
+		event.Period = now.Sub(stats.LastTimestamp)
+
+		stats.LastPeriod = event.Period
+		if stats.Count == 1 || event.Period < stats.MinPeriod {
+			stats.MinPeriod = event.Period
+		}
+		if event.Period > stats.MaxPeriod {
+			stats.MaxPeriod = event.Period
+		}
+		stats.periodsSum += event.Period
+		stats.MeanPeriod = stats.periodsSum / time.Duration(stats.Count)
+
+		if consumer.Period > 0 {
+			event.Jitter = event.Period - consumer.Period
+
+			jitter := event.Jitter
+			if jitter < 0 {
+				jitter = -jitter
+			}
+			if jitter > stats.MaxJitter {
+				stats.MaxJitter = jitter
+			}
+
+			if event.Period > consumer.Period*3/2 {
+				event.Missed = int((event.Period+consumer.Period/2)/consumer.Period) - 1
+				stats.Missed += uint64(event.Missed)
+			}
+		}
+	}
+
+	stats.Count++
+	stats.LastTimestamp = now
+	stats.LastCounter = event.Counter
+
+	pdoMaps := []*PDOMap{}
+	for _, syncMap := range consumer.pdoMaps {
+		syncMap.syncs++
+		if syncMap.pdoMap.TransType == 0 || syncMap.syncs >= int(syncMap.pdoMap.TransType) {
+			syncMap.syncs = 0
+			pdoMaps = append(pdoMaps, syncMap.pdoMap)
+		}
+	}
+	windowLength := consumer.WindowLength
+
+	consumer.Unlock()
+
+	violations := consumer.transmitPDOMaps(pdoMaps, now, windowLength)
+
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	event.WindowViolations = violations
+	consumer.stats.WindowViolations += uint64(violations)
+
+	for _, eventsChan := range consumer.eventsChans {
+		select {
+		case eventsChan.C <- event:
+		default:
+		}
+	}
+}
+
+// transmitPDOMaps transmit pdoMaps during the synchronous window of the sync received at syncTime,
+// maps not transmitted in the window are counted as violations
+func (consumer *SyncConsumer) transmitPDOMaps(pdoMaps []*PDOMap, syncTime time.Time, windowLength time.Duration) int {
+	violations := 0
+
+	for _, pdoMap := range pdoMaps {
+		if windowLength > 0 && time.Since(syncTime) > windowLength {
+			violations++
+			continue
+		}
+
+		pdoMap.Lock()
+		if pdoMap.TransType == 0 {
+			pdoMap.RebuildData()
+			if string(pdoMap.OldData) == string(pdoMap.Data) {
+				pdoMap.Unlock()
+				continue
+			}
+		}
+		pdoMap.Transmit(pdoMap.TransType != 0)
+		pdoMap.Unlock()
+	}
+
+	return violations
+}
+
+// AcquireEventsChan create a new SyncEventsChan
+func (consumer *SyncConsumer) AcquireEventsChan() *SyncEventsChan {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	eventsChan := &SyncEventsChan{
+		chanID: uuid.Must(uuid.NewRandom()).String(),
+		C:      make(chan SyncEvent, syncEventsChanSize),
+	}
+
+	consumer.eventsChans = append(consumer.eventsChans, eventsChan)
+
+	return eventsChan
+}
+
+// ReleaseEventsChan release (close) a SyncEventsChan
+func (consumer *SyncConsumer) ReleaseEventsChan(id string) error {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	for idx, eventsChan := range consumer.eventsChans {
+		if eventsChan.chanID == id {
+			close(eventsChan.C)
+			consumer.eventsChans = append(
+				consumer.eventsChans[:idx],
+				consumer.eventsChans[idx+1:]...,
+			)
+			return nil
+		}
+	}
+
+	return errors.New("no SyncEventsChan found with specified ID")
+}
@@ -0,0 +1,141 @@
+package canopen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncConsumer(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	consumer := network.SyncConsumer
+	consumer.Period = 20 * time.Millisecond
+
+	assert.NoError(t, consumer.Listen())
+
+	eventsChan := consumer.AcquireEventsChan()
+	defer consumer.ReleaseEventsChan(eventsChan.chanID)
+
+	waitSync := func() SyncEvent {
+		t.Helper()
+
+		select {
+		case event := <-eventsChan.C:
+			return event
+		case <-time.After(200 * time.Millisecond):
+			t.Fatal("no sync received")
+		}
+
+		return SyncEvent{}
+	}
+
+	network.Send(0x80, []byte{1})
+	event := waitSync()
+	assert.Equal(t, 1, event.Counter)
+	assert.Equal(t, time.Duration(0), event.Period)
+
+	time.Sleep(20 * time.Millisecond)
+	network.Send(0x80, []byte{2})
+	event = waitSync()
+	assert.Equal(t, 2, event.Counter)
+	assert.InDelta(t, float64(20*time.Millisecond), float64(event.Period), float64(10*time.Millisecond))
+	assert.Equal(t, 0, event.Missed)
+
+	// Two syncs missed
+	time.Sleep(60 * time.Millisecond)
+	network.Send(0x80, []byte{})
+	event = waitSync()
+	assert.Equal(t, -1, event.Counter)
+	assert.Equal(t, 2, event.Missed)
+
+	stats := consumer.Stats()
+	assert.Equal(t, uint64(3), stats.Count)
+	assert.Equal(t, uint64(2), stats.Missed)
+	assert.Equal(t, -1, stats.LastCounter)
+	assert.GreaterOrEqual(t, stats.MaxJitter, 40*time.Millisecond)
+
+	consumer.Unlisten()
+	network.Send(0x80, []byte{3})
+	select {
+	case <-eventsChan.C:
+		t.Fatal("sync received after Unlisten")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSyncConsumer_PDOMap(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	node := network.AddNode(NewNode(5, nil, nil), NewDicObjectDic(), false)
+	consumer := network.SyncConsumer
+
+	variable := &DicVariable{Index: 0x2000, DataType: Unsigned16, Data: []byte{0x01, 0x02}}
+	pdoMap := NewPDOMap(node.PDONode, nil, nil)
+	pdoMap.CobID = 0x205
+	pdoMap.TransType = 2
+	pdoMap.Map = map[int]DicObject{1: variable}
+
+	assert.NoError(t, consumer.AddPDOMap(pdoMap))
+	assert.NoError(t, consumer.Listen())
+
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == 0x205
+	}
+	framesChan := network.AcquireFramesChan(&filterFunc)
+	defer network.ReleaseFramesChan(framesChan.ID)
+
+	eventsChan := consumer.AcquireEventsChan()
+	defer consumer.ReleaseEventsChan(eventsChan.chanID)
+
+	// Transmitted every 2 syncs
+	for i := 0; i < 4; i++ {
+		network.Send(0x80, []byte{})
+		<-eventsChan.C
+	}
+	assert.Equal(t, []byte{0x01, 0x02}, waitFrame(t, framesChan, 50*time.Millisecond).GetData())
+	assert.Equal(t, []byte{0x01, 0x02}, waitFrame(t, framesChan, 50*time.Millisecond).GetData())
+	assert.Empty(t, framesChan.C)
+
+	// Acyclic, transmitted on change only
+	pdoMap.TransType = 0
+	network.Send(0x80, []byte{})
+	<-eventsChan.C
+	assert.Empty(t, framesChan.C)
+
+	variable.SetData([]byte{0x03, 0x04})
+	network.Send(0x80, []byte{})
+	<-eventsChan.C
+	assert.Equal(t, []byte{0x03, 0x04}, waitFrame(t, framesChan, 50*time.Millisecond).GetData())
+
+	consumer.RemovePDOMap(pdoMap)
+	variable.SetData([]byte{0x05, 0x06})
+	network.Send(0x80, []byte{})
+	<-eventsChan.C
+	assert.Empty(t, framesChan.C)
+
+	pdoMap.TransType = 254
+	assert.Error(t, consumer.AddPDOMap(pdoMap))
+}
+
+func TestSyncConsumer_WindowLength(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	consumer := network.SyncConsumer
+
+	dic := NewDicObjectDic()
+	dic.AddObject(&DicVariable{Index: 0x1005, DataType: Unsigned32, Data: []byte{0x80, 0x00, 0x00, 0x00}})
+	dic.AddObject(&DicVariable{Index: 0x1006, DataType: Unsigned32, Data: []byte{0x10, 0x27, 0x00, 0x00}})
+	dic.AddObject(&DicVariable{Index: 0x1007, DataType: Unsigned32, Data: []byte{0x01, 0x00, 0x00, 0x00}})
+
+	assert.NoError(t, consumer.Configure(dic))
+	assert.Equal(t, 10*time.Millisecond, consumer.Period)
+	assert.Equal(t, time.Microsecond, consumer.WindowLength)
+
+	pdoMap := NewPDOMap(nil, nil, nil)
+	pdoMap.TransType = 1
+	assert.NoError(t, consumer.AddPDOMap(pdoMap))
+
+	// The window is over before the map is transmitted
+	consumer.handleSync([]byte{}, time.Now().Add(-time.Millisecond))
+	assert.Equal(t, uint64(1), consumer.Stats().WindowViolations)
+}
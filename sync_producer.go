@@ -0,0 +1,263 @@
+package canopen
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+// SyncCobIDGenerate is the bit 30 of the sync COB-ID 0x1005, set when the node generates sync
+const SyncCobIDGenerate uint32 = 0x40000000
+
+// SyncStats are the statistics of the sync messages sent
+type SyncStats struct {
+	// Count of sync messages sent
+	Count uint64
+	// LastTimestamp is the time the last sync was sent
+	LastTimestamp time.Time
+
+	// Actual periods between two sync messages
+	LastPeriod time.Duration
+	MinPeriod  time.Duration
+	MaxPeriod  time.Duration
+	MeanPeriod time.Duration
+
+	// MaxJitter is the maximum difference between an actual period and the configured one
+	MaxJitter time.Duration
+
+	periodsSum time.Duration
+}
+
+// SyncProducer send sync messages every Period
+type SyncProducer struct {
+	sync.Mutex
+
+	Network *Network
+	// CobID of sync messages, 0x80 by default
+	CobID uint32
+	// Period between sync messages, the communication cycle period 0x1006
+	Period time.Duration
+	// CounterOverflow is the synchronous counter overflow value 0x1019, 0 to send
+	// sync without counter, else the counter goes from 1 to CounterOverflow
+	CounterOverflow uint8
+
+	counter  uint8
+	stats    SyncStats
+	running  bool
+	stopChan chan bool
+	doneChan chan bool
+}
+
+// NewSyncProducer return a new SyncProducer on the default COB-ID 0x80
+func NewSyncProducer(network *Network) *SyncProducer {
+	return &SyncProducer{
+		Network: network,
+		CobID:   0x80,
+	}
+}
+
+// Configure the producer with the sync COB-ID 0x1005, the communication cycle period 0x1006 and
+// the synchronous counter overflow value 0x1019 of objectDic. The period is 0 if the COB-ID
+// does not have the generate bit.
+func (producer *SyncProducer) Configure(objectDic *DicObjectDic) error {
+	producer.Lock()
+	defer producer.Unlock()
+
+	if producer.running {
+		return errors.New("sync producer is running")
+	}
+
+	object := objectDic.FindIndex(0x1005)
+	if object == nil || len(object.GetData()) != 4 {
+		return errors.New("sync COB-ID 0x1005 not found")
+	}
+	cobID := binary.LittleEndian.Uint32(object.GetData())
+
+	var period time.Duration
+	if object := objectDic.FindIndex(0x1006); object != nil && len(object.GetData()) == 4 {
+		period = time.Duration(binary.LittleEndian.Uint32(object.GetData())) * time.Microsecond
+	}
+
+	var counterOverflow uint8
+	if object := objectDic.FindIndex(0x1019); object != nil && len(object.GetData()) == 1 {
+		counterOverflow = object.GetData()[0]
+	}
+
+	if counterOverflow == 1 || counterOverflow > 240 {
+		return errors.New("invalid synchronous counter overflow value")
+	}
+
+	producer.CobID = cobID & 0x7FF
+	producer.CounterOverflow = counterOverflow
+	producer.Period = 0
+	if cobID&SyncCobIDGenerate != 0 {
+		producer.Period = period
+	}
+
+	return nil
+}
+
+// Start sending sync messages every Period, the counter restart at 1
+func (producer *SyncProducer) Start() error {
+	producer.Lock()
+	defer producer.Unlock()
+
+	if producer.running {
+		return nil
+	}
+
+	if producer.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	if producer.Period <= 0 {
+		return errors.New("sync period not set")
+	}
+
+	if producer.CounterOverflow == 1 || producer.CounterOverflow > 240 {
+		return errors.New("invalid synchronous counter overflow value")
+	}
+
+	producer.counter = 0
+	producer.stats = SyncStats{}
+	producer.running = true
+	producer.stopChan = make(chan bool)
+	producer.doneChan = make(chan bool)
+
+	go producer.run(producer.Period, producer.stopChan, producer.doneChan)
+
+	return nil
+}
+
+// Stop sending sync messages, return once the last sync is sent
+func (producer *SyncProducer) Stop() {
+	producer.Lock()
+
+	if !producer.running {
+		producer.Unlock()
+		return
+	}
+
+	producer.running = false
+	close(producer.stopChan)
+	doneChan := producer.doneChan
+	producer.Unlock()
+
+	<-doneChan
+}
+
+// Running returns true if the producer is sending sync messages
+func (producer *SyncProducer) Running() bool {
+	producer.Lock()
+	defer producer.Unlock()
+
+	return producer.running
+}
+
+// SetPeriod change the period between sync messages, the producer is restarted if running
+func (producer *SyncProducer) SetPeriod(period time.Duration) error {
+	running := producer.Running()
+	producer.Stop()
+
+	producer.Lock()
+	producer.Period = period
+	producer.Unlock()
+
+	if running && period > 0 {
+		return producer.Start()
+	}
+
+	return nil
+}
+
+// Stats returns the statistics of the sync messages sent since Start
+func (producer *SyncProducer) Stats() SyncStats {
+	producer.Lock()
+	defer producer.Unlock()
+
+	return producer.stats
+}
+
+// Transmit send one sync message, with the next counter value if enabled
+func (producer *SyncProducer) Transmit() error {
+	if producer.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	producer.Lock()
+
+	data := []byte{}
+	if producer.CounterOverflow > 0 {
+		producer.counter++
+		if producer.counter > producer.CounterOverflow {
+			producer.counter = 1
+		}
+		data = []byte{producer.counter}
+	}
+	cobID := producer.CobID
+	producer.Unlock()
+
+	return producer.Network.Send(cobID, data)
+}
+
+// run send sync messages at start + n * period, so delays do not accumulate.
+// Periods missed are skipped.
+func (producer *SyncProducer) run(period time.Duration, stopChan chan bool, doneChan chan bool) {
+	defer close(doneChan)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	next := time.Now()
+
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-timer.C:
+		}
+
+		producer.Transmit()
+		producer.updateStats(period, time.Now())
+
+		next = next.Add(period)
+		if now := time.Now(); next.Before(now) {
+			next = next.Add(now.Sub(next).Truncate(period) + period)
+		}
+		timer.Reset(time.Until(next))
+	}
+}
+
+func (producer *SyncProducer) updateStats(period time.Duration, now time.Time) {
+	producer.Lock()
+	defer producer.Unlock()
+
+	stats := &producer.stats
+
+	if stats.Count > 0 {
+		actual := now.Sub(stats.LastTimestamp)
+
+		stats.LastPeriod = actual
+		if stats.Count == 1 || actual < stats.MinPeriod {
+			stats.MinPeriod = actual
+		}
+		if actual > stats.MaxPeriod {
+			stats.MaxPeriod = actual
+		}
+
+		stats.periodsSum += actual
+		stats.MeanPeriod = stats.periodsSum / time.Duration(stats.Count)
+
+		jitter := actual - period
+		if jitter < 0 {
+			jitter = -jitter
+		}
+		if jitter > stats.MaxJitter {
+			stats.MaxJitter = jitter
+		}
+	}
+
+	stats.Count++
+	stats.LastTimestamp = now
+}
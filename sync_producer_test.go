@@ -0,0 +1,77 @@
+package canopen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncProducer(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	producer := network.SyncProducer
+
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == 0x80
+	}
+	framesChan := network.AcquireFramesChan(&filterFunc)
+	defer network.ReleaseFramesChan(framesChan.ID)
+
+	assert.Error(t, producer.Start())
+
+	producer.CounterOverflow = 3
+	assert.NoError(t, producer.SetPeriod(10*time.Millisecond))
+	assert.NoError(t, producer.Start())
+	assert.True(t, producer.Running())
+
+	for _, counter := range []byte{1, 2, 3, 1, 2} {
+		assert.Equal(t, []byte{counter}, waitFrame(t, framesChan, 50*time.Millisecond).GetData())
+	}
+
+	producer.Stop()
+	assert.False(t, producer.Running())
+
+	stats := producer.Stats()
+	assert.GreaterOrEqual(t, stats.Count, uint64(5))
+	assert.InDelta(t, float64(10*time.Millisecond), float64(stats.MeanPeriod), float64(5*time.Millisecond))
+	assert.LessOrEqual(t, stats.MinPeriod, stats.MeanPeriod)
+	assert.GreaterOrEqual(t, stats.MaxPeriod, stats.MeanPeriod)
+
+	// Without counter
+	for len(framesChan.C) > 0 {
+		<-framesChan.C
+	}
+	producer.CounterOverflow = 0
+	assert.NoError(t, producer.Transmit())
+	assert.Empty(t, waitFrame(t, framesChan, 50*time.Millisecond).GetData())
+
+	producer.CounterOverflow = 1
+	assert.Error(t, producer.Start())
+}
+
+func TestSyncProducer_Configure(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	producer := network.SyncProducer
+
+	dic := NewDicObjectDic()
+	cobID := &DicVariable{Index: 0x1005, DataType: Unsigned32, Data: []byte{0x81, 0x00, 0x00, 0x40}}
+	dic.AddObject(cobID)
+	dic.AddObject(&DicVariable{Index: 0x1006, DataType: Unsigned32, Data: []byte{0x10, 0x27, 0x00, 0x00}})
+	dic.AddObject(&DicVariable{Index: 0x1019, DataType: Unsigned8, Data: []byte{10}})
+
+	assert.NoError(t, producer.Configure(dic))
+	assert.Equal(t, uint32(0x81), producer.CobID)
+	assert.Equal(t, 10*time.Millisecond, producer.Period)
+	assert.Equal(t, uint8(10), producer.CounterOverflow)
+
+	// Not a sync producer
+	cobID.SetData([]byte{0x81, 0x00, 0x00, 0x00})
+	assert.NoError(t, producer.Configure(dic))
+	assert.Equal(t, time.Duration(0), producer.Period)
+
+	dic.FindIndex(0x1019).SetData([]byte{241})
+	assert.Error(t, producer.Configure(dic))
+
+	assert.Error(t, producer.Configure(NewDicObjectDic()))
+}
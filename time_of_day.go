@@ -0,0 +1,153 @@
+package canopen
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// TimeOfDayEpoch is the origin of TIME_OF_DAY days, 1984-01-01
+var TimeOfDayEpoch = time.Date(1984, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// timeOfDayMsMask is the mask of the 28 bits milliseconds of TIME_OF_DAY and TIME_DIFFERENCE
+const timeOfDayMsMask uint32 = 0x0FFFFFFF
+
+// dayDuration is the duration of a TIME_OF_DAY day
+const dayDuration = 24 * time.Hour
+
+// timeOfDayMax is the last millisecond of the 65535th day, the greatest value of TIME_OF_DAY
+// and TIME_DIFFERENCE
+const timeOfDayMax = 65536*dayDuration - time.Millisecond
+
+// encodeTimeOfDayDuration encode d as days and milliseconds, clamped to 0 and timeOfDayMax
+func encodeTimeOfDayDuration(d time.Duration) []byte {
+	if d < 0 {
+		d = 0
+	} else if d > timeOfDayMax {
+		d = timeOfDayMax
+	}
+
+	days := d / dayDuration
+
+	return encodeTimeOfDayFields(uint32((d-days*dayDuration)/time.Millisecond), uint16(days))
+}
+
+// encodeTimeOfDayFields encode milliseconds and days in 6 bytes
+func encodeTimeOfDayFields(ms uint32, days uint16) []byte {
+	data := make([]byte, 6)
+	binary.LittleEndian.PutUint32(data[0:4], ms&timeOfDayMsMask)
+	binary.LittleEndian.PutUint16(data[4:6], days)
+
+	return data
+}
+
+// decodeTimeOfDayFields decode milliseconds and days from 6 bytes
+func decodeTimeOfDayFields(data []byte) (uint32, uint16, error) {
+	if len(data) != 6 {
+		return 0, 0, fmt.Errorf("%w: time has %d bytes, want 6", ErrSDODataLength, len(data))
+	}
+
+	return binary.LittleEndian.Uint32(data[0:4]) & timeOfDayMsMask, binary.LittleEndian.Uint16(data[4:6]), nil
+}
+
+// EncodeTimeOfDay encode t as TIME_OF_DAY: milliseconds after midnight and days since 1984-01-01, in UTC.
+// Times before 1984 are encoded as 1984-01-01, times after the 65535th day (in 2163) as its last millisecond.
+func EncodeTimeOfDay(t time.Time) []byte {
+	return encodeTimeOfDayDuration(t.UTC().Sub(TimeOfDayEpoch))
+}
+
+// DecodeTimeOfDay decode a TIME_OF_DAY as an UTC time
+func DecodeTimeOfDay(data []byte) (time.Time, error) {
+	ms, days, err := decodeTimeOfDayFields(data)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return TimeOfDayEpoch.Add(time.Duration(days)*dayDuration + time.Duration(ms)*time.Millisecond), nil
+}
+
+// EncodeTimeDifference encode d as TIME_DIFFERENCE: days and milliseconds, with a millisecond resolution.
+// Negative durations are encoded as 0, durations over 65535 days (about 179 years) as 65535 days and 86399999ms.
+func EncodeTimeDifference(d time.Duration) []byte {
+	return encodeTimeOfDayDuration(d)
+}
+
+// DecodeTimeDifference decode a TIME_DIFFERENCE
+func DecodeTimeDifference(data []byte) (time.Duration, error) {
+	ms, days, err := decodeTimeOfDayFields(data)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(days)*dayDuration + time.Duration(ms)*time.Millisecond, nil
+}
+
+// ReadTimeOfDay read a TIME_OF_DAY value
+func (sdoClient *SDOClient) ReadTimeOfDay(index uint16, subIndex uint8, opts ...SDOOption) (time.Time, error) {
+	data, err := sdoClient.readType(index, subIndex, TimeOfDay, opts...)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return DecodeTimeOfDay(data)
+}
+
+// ReadTimeOfDayByName read a TIME_OF_DAY variable found by name
+func (sdoClient *SDOClient) ReadTimeOfDayByName(name string, opts ...SDOOption) (time.Time, error) {
+	variable, err := sdoClient.FindVariable(name, TimeOfDay)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return sdoClient.ReadTimeOfDay(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteTimeOfDay write a TIME_OF_DAY value
+func (sdoClient *SDOClient) WriteTimeOfDay(index uint16, subIndex uint8, value time.Time, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, EncodeTimeOfDay(value), opts...)
+}
+
+// WriteTimeOfDayByName write a TIME_OF_DAY variable found by name
+func (sdoClient *SDOClient) WriteTimeOfDayByName(name string, value time.Time, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, TimeOfDay)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteTimeOfDay(variable.Index, variable.SubIndex, value, opts...)
+}
+
+// ReadTimeDifference read a TIME_DIFFERENCE value
+func (sdoClient *SDOClient) ReadTimeDifference(index uint16, subIndex uint8, opts ...SDOOption) (time.Duration, error) {
+	data, err := sdoClient.readType(index, subIndex, TimeDifference, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return DecodeTimeDifference(data)
+}
+
+// ReadTimeDifferenceByName read a TIME_DIFFERENCE variable found by name
+func (sdoClient *SDOClient) ReadTimeDifferenceByName(name string, opts ...SDOOption) (time.Duration, error) {
+	variable, err := sdoClient.FindVariable(name, TimeDifference)
+	if err != nil {
+		return 0, err
+	}
+
+	return sdoClient.ReadTimeDifference(variable.Index, variable.SubIndex, opts...)
+}
+
+// WriteTimeDifference write a TIME_DIFFERENCE value
+func (sdoClient *SDOClient) WriteTimeDifference(index uint16, subIndex uint8, value time.Duration, opts ...SDOOption) error {
+	return sdoClient.Write(index, subIndex, false, EncodeTimeDifference(value), opts...)
+}
+
+// WriteTimeDifferenceByName write a TIME_DIFFERENCE variable found by name
+func (sdoClient *SDOClient) WriteTimeDifferenceByName(name string, value time.Duration, opts ...SDOOption) error {
+	variable, err := sdoClient.FindVariable(name, TimeDifference)
+	if err != nil {
+		return err
+	}
+
+	return sdoClient.WriteTimeDifference(variable.Index, variable.SubIndex, value, opts...)
+}
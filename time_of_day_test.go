@@ -0,0 +1,80 @@
+package canopen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeOfDay(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 12, 30, 15, 250*int(time.Millisecond), time.UTC)
+
+	// 14674 days since 1984-01-01, 45015250ms after midnight
+	data := EncodeTimeOfDay(date)
+	assert.Equal(t, []byte{0xD2, 0xE0, 0xAE, 0x02, 0x52, 0x39}, data)
+
+	decoded, err := DecodeTimeOfDay(data)
+	assert.NoError(t, err)
+	assert.True(t, date.Equal(decoded))
+
+	// Encoded in UTC, with a millisecond resolution
+	local := date.In(time.FixedZone("UTC+2", 2*3600)).Add(999 * time.Microsecond)
+	assert.Equal(t, data, EncodeTimeOfDay(local))
+
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0}, EncodeTimeOfDay(time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, []byte{0xFF, 0x5B, 0x26, 0x05, 0xFF, 0xFF}, EncodeTimeOfDay(time.Date(2200, time.January, 1, 0, 0, 0, 0, time.UTC)))
+
+	// Reserved bits are ignored
+	decoded, err = DecodeTimeOfDay([]byte{0xD2, 0xE0, 0xAE, 0xF2, 0x52, 0x39})
+	assert.NoError(t, err)
+	assert.True(t, date.Equal(decoded))
+
+	_, err = DecodeTimeOfDay([]byte{0x00})
+	assert.ErrorIs(t, err, ErrSDODataLength)
+}
+
+func TestTimeDifference(t *testing.T) {
+	d := 3*24*time.Hour + 90*time.Minute + 5*time.Millisecond
+
+	data := EncodeTimeDifference(d)
+	assert.Equal(t, []byte{0xC5, 0x65, 0x52, 0x00, 0x03, 0x00}, data)
+
+	decoded, err := DecodeTimeDifference(data)
+	assert.NoError(t, err)
+	assert.Equal(t, d, decoded)
+
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0}, EncodeTimeDifference(-time.Second))
+	assert.Equal(t, []byte{0xFF, 0x5B, 0x26, 0x05, 0xFF, 0xFF}, EncodeTimeDifference(70000*24*time.Hour))
+
+	_, err = DecodeTimeDifference(nil)
+	assert.ErrorIs(t, err, ErrSDODataLength)
+}
+
+func TestSDOClient_TimeOfDay(t *testing.T) {
+	localNode, node := getLocalAndRemoteNodes(t)
+	localNode.ObjectDic.AddObject(&DicVariable{Index: 0x2010, Name: "Last service", DataType: TimeOfDay, AccessType: "rw", Data: make([]byte, 6)})
+	localNode.ObjectDic.AddObject(&DicVariable{Index: 0x2011, Name: "Uptime", DataType: TimeDifference, AccessType: "rw", Data: make([]byte, 6)})
+	node.SetObjectDic(localNode.ObjectDic)
+
+	date := time.Date(2024, time.March, 5, 12, 30, 15, 0, time.UTC)
+	assert.NoError(t, node.SDOClient.WriteTimeOfDayByName("Last service", date))
+
+	value, err := node.SDOClient.ReadTimeOfDay(0x2010, 0)
+	assert.NoError(t, err)
+	assert.True(t, date.Equal(value))
+
+	assert.NoError(t, node.SDOClient.WriteTimeDifference(0x2011, 0, 36*time.Hour))
+
+	uptime, err := node.SDOClient.ReadTimeDifferenceByName("Uptime")
+	assert.NoError(t, err)
+	assert.Equal(t, 36*time.Hour, uptime)
+
+	_, err = node.SDOClient.ReadTimeOfDayByName("Uptime")
+	assert.ErrorIs(t, err, ErrSDODataType)
+
+	// Wrong length
+	assertSDOAbortCode(t, node.SDOClient.Write(0x2010, 0, false, []byte{1, 2, 3, 4}), SDOAbortTypeLengthTooLow)
+	_, err = node.SDOClient.ReadTimeOfDay(0x1000, 0)
+	assert.ErrorIs(t, err, ErrSDODataLength)
+}
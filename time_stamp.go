@@ -0,0 +1,293 @@
+package canopen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaster-prj/go-can"
+)
+
+// timeEventsChanSize is the buffer size of time events chans
+const timeEventsChanSize = 16
+
+// TimeProducer send the time stamp object, with the TIME_OF_DAY of Now
+type TimeProducer struct {
+	sync.Mutex
+
+	Network *Network
+	// CobID of time messages, 0x100 by default
+	CobID uint32
+	// Now returns the time to send, time.Now by default
+	Now func() time.Time
+
+	running  bool
+	stopChan chan bool
+}
+
+// NewTimeProducer return a new TimeProducer on the default COB-ID 0x100
+func NewTimeProducer(network *Network) *TimeProducer {
+	return &TimeProducer{
+		Network: network,
+		CobID:   0x100,
+		Now:     time.Now,
+	}
+}
+
+// Transmit send the current time
+func (producer *TimeProducer) Transmit() error {
+	producer.Lock()
+	now := producer.Now
+	producer.Unlock()
+
+	return producer.TransmitTime(now())
+}
+
+// TransmitTime send t as time stamp
+func (producer *TimeProducer) TransmitTime(t time.Time) error {
+	if producer.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	producer.Lock()
+	cobID := producer.CobID
+	producer.Unlock()
+
+	return producer.Network.Send(cobID, EncodeTimeOfDay(t))
+}
+
+// Start sending the current time every period
+func (producer *TimeProducer) Start(period time.Duration) error {
+	producer.Lock()
+	defer producer.Unlock()
+
+	if producer.running {
+		return nil
+	}
+
+	if producer.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	if period <= 0 {
+		return errors.New("time period must be positive")
+	}
+
+	producer.running = true
+	producer.stopChan = make(chan bool)
+
+	go producer.run(period, producer.stopChan)
+
+	return nil
+}
+
+// Stop sending the current time
+func (producer *TimeProducer) Stop() {
+	producer.Lock()
+	defer producer.Unlock()
+
+	if !producer.running {
+		return
+	}
+
+	producer.running = false
+	close(producer.stopChan)
+}
+
+func (producer *TimeProducer) run(period time.Duration, stopChan chan bool) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		producer.Transmit()
+
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// TimeEvent is sent for each time stamp received
+type TimeEvent struct {
+	// Time is the time stamp sent
+	Time time.Time
+	// Timestamp is the reception time
+	Timestamp time.Time
+}
+
+type TimeEventsChan struct {
+	chanID string
+	C      chan TimeEvent
+}
+
+// TimeConsumer receive time stamp objects
+type TimeConsumer struct {
+	sync.Mutex
+
+	Network *Network
+	// CobID of time messages, 0x100 by default
+	CobID uint32
+
+	last        *TimeEvent
+	eventsChans []*TimeEventsChan
+
+	listening    bool
+	stopChan     chan bool
+	framesChanID string
+}
+
+// NewTimeConsumer return a new TimeConsumer on the default COB-ID 0x100
+func NewTimeConsumer(network *Network) *TimeConsumer {
+	return &TimeConsumer{
+		Network: network,
+		CobID:   0x100,
+	}
+}
+
+// Listen for time stamps on network
+func (consumer *TimeConsumer) Listen() error {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	if consumer.Network == nil {
+		return errors.New("no network defined")
+	}
+
+	if consumer.listening {
+		return nil
+	}
+
+	cobID := consumer.CobID
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == cobID
+	}
+
+	framesChan := consumer.Network.AcquireFramesChan(&filterFunc)
+	consumer.framesChanID = framesChan.ID
+	consumer.stopChan = make(chan bool)
+	consumer.listening = true
+
+	go func(stopChan chan bool) {
+		for {
+			select {
+			case <-stopChan:
+				return
+			case frm, ok := <-framesChan.C:
+				if !ok {
+					return
+				}
+				consumer.handleTime(frm.GetData(), time.Now())
+			}
+		}
+	}(consumer.stopChan)
+
+	return nil
+}
+
+// Unlisten stop listening time stamps
+func (consumer *TimeConsumer) Unlisten() {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	if !consumer.listening {
+		return
+	}
+
+	close(consumer.stopChan)
+	consumer.Network.ReleaseFramesChan(consumer.framesChanID)
+	consumer.listening = false
+}
+
+func (consumer *TimeConsumer) handleTime(data []byte, now time.Time) {
+	t, err := DecodeTimeOfDay(data)
+	if err != nil {
+		return
+	}
+
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	event := TimeEvent{Time: t, Timestamp: now}
+	consumer.last = &event
+
+	for _, eventsChan := range consumer.eventsChans {
+		select {
+		case eventsChan.C <- event:
+		default:
+		}
+	}
+}
+
+// Last returns the last time stamp received, nil if none
+func (consumer *TimeConsumer) Last() *TimeEvent {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	if consumer.last == nil {
+		return nil
+	}
+
+	last := *consumer.last
+	return &last
+}
+
+// Now returns the time of the network, the last time stamp plus the time elapsed since its reception
+func (consumer *TimeConsumer) Now() (time.Time, error) {
+	last := consumer.Last()
+	if last == nil {
+		return time.Time{}, errors.New("no time stamp received")
+	}
+
+	return last.Time.Add(time.Since(last.Timestamp)), nil
+}
+
+// Wait return the next time stamp received, or ctx error when ctx is done
+func (consumer *TimeConsumer) Wait(ctx context.Context) (*TimeEvent, error) {
+	eventsChan := consumer.AcquireEventsChan()
+	defer consumer.ReleaseEventsChan(eventsChan.chanID)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case event := <-eventsChan.C:
+		return &event, nil
+	}
+}
+
+// AcquireEventsChan create a new TimeEventsChan
+func (consumer *TimeConsumer) AcquireEventsChan() *TimeEventsChan {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	eventsChan := &TimeEventsChan{
+		chanID: uuid.Must(uuid.NewRandom()).String(),
+		C:      make(chan TimeEvent, timeEventsChanSize),
+	}
+
+	consumer.eventsChans = append(consumer.eventsChans, eventsChan)
+
+	return eventsChan
+}
+
+// ReleaseEventsChan release (close) a TimeEventsChan
+func (consumer *TimeConsumer) ReleaseEventsChan(id string) error {
+	consumer.Lock()
+	defer consumer.Unlock()
+
+	for idx, eventsChan := range consumer.eventsChans {
+		if eventsChan.chanID == id {
+			close(eventsChan.C)
+			consumer.eventsChans = append(
+				consumer.eventsChans[:idx],
+				consumer.eventsChans[idx+1:]...,
+			)
+			return nil
+		}
+	}
+
+	return errors.New("no TimeEventsChan found with specified ID")
+}
@@ -0,0 +1,76 @@
+package canopen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jaster-prj/go-can"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeProducer(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	producer := network.TimeProducer
+
+	date := time.Date(2024, time.March, 5, 12, 30, 15, 0, time.UTC)
+	producer.Now = func() time.Time { return date }
+
+	filterFunc := func(frm *can.Frame) bool {
+		return frm.ArbitrationID == 0x100
+	}
+	framesChan := network.AcquireFramesChan(&filterFunc)
+	defer network.ReleaseFramesChan(framesChan.ID)
+
+	assert.NoError(t, producer.Transmit())
+	assert.Equal(t, EncodeTimeOfDay(date), waitFrame(t, framesChan, 50*time.Millisecond).GetData())
+
+	assert.Error(t, producer.Start(0))
+	assert.NoError(t, producer.Start(10*time.Millisecond))
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, EncodeTimeOfDay(date), waitFrame(t, framesChan, 50*time.Millisecond).GetData())
+	}
+	producer.Stop()
+}
+
+func TestTimeConsumer(t *testing.T) {
+	network := getLoopbackNetwork(t)
+	consumer := network.TimeConsumer
+
+	_, err := consumer.Now()
+	assert.Error(t, err)
+	assert.Nil(t, consumer.Last())
+
+	assert.NoError(t, consumer.Listen())
+
+	eventsChan := consumer.AcquireEventsChan()
+	defer consumer.ReleaseEventsChan(eventsChan.chanID)
+
+	date := time.Date(2024, time.March, 5, 12, 30, 15, 0, time.UTC)
+
+	// Invalid frames are ignored
+	network.Send(0x100, []byte{0x00, 0x01})
+	assert.NoError(t, network.TimeProducer.TransmitTime(date))
+
+	select {
+	case event := <-eventsChan.C:
+		assert.True(t, date.Equal(event.Time))
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("no time received")
+	}
+
+	if last := consumer.Last(); assert.NotNil(t, last) {
+		assert.True(t, date.Equal(last.Time))
+	}
+
+	now, err := consumer.Now()
+	assert.NoError(t, err)
+	assert.WithinDuration(t, date, now, 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	consumer.Unlisten()
+	_, err = consumer.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,24 @@
+package utils
+
+// CRC16CCITT compute the CRC-16-CCITT (XMODEM) of data,
+// with polynomial 0x1021 and an initial value of 0
+func CRC16CCITT(data []byte) uint16 {
+	return UpdateCRC16CCITT(0, data)
+}
+
+// UpdateCRC16CCITT continue the CRC-16-CCITT computation of crc with data
+func UpdateCRC16CCITT(crc uint16, data []byte) uint16 {
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+
+		for i := 0; i < 8; i++ {
+			if (crc & 0x8000) != 0 {
+				crc = (crc << 1) ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestCRC16CCITT(t *testing.T) {
+	data := []byte("123456789")
+
+	if crc := CRC16CCITT(data); crc != 0x31C3 {
+		t.Fatalf("CRC16CCITT with %v should return 0x31C3, got 0x%04X", data, crc)
+	}
+}
+
+func TestUpdateCRC16CCITT(t *testing.T) {
+	data := []byte("123456789")
+
+	crc := UpdateCRC16CCITT(0, data[:4])
+	crc = UpdateCRC16CCITT(crc, data[4:])
+
+	if crc != CRC16CCITT(data) {
+		t.Fatalf("UpdateCRC16CCITT in two parts should return 0x%04X, got 0x%04X", CRC16CCITT(data), crc)
+	}
+}